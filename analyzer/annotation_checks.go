@@ -0,0 +1,399 @@
+// analyzer/annotation_checks.go
+package analyzer
+
+import "fmt"
+
+// objectMethods métodos de Object que cualquier tipo puede sobrescribir,
+// por nombre y número de parámetros
+var objectMethods = map[string]bool{
+	"toString/0": true, "hashCode/0": true, "equals/1": true,
+	"clone/0": true, "finalize/0": true,
+}
+
+// methodSignature identifica un método por su nombre y su número de
+// parámetros; los tipos de los parámetros no se comparan
+func methodSignature(method *MethodDecl) string {
+	return fmt.Sprintf("%s/%d", method.Name, len(method.Params))
+}
+
+// checkAnnotations comprueba las anotaciones de la unidad: sus valores, los
+// elementos a los que se aplican y las reglas de @Override,
+// @FunctionalInterface y @SafeVarargs
+func (c *checker) checkAnnotations(unit *CompilationUnit) {
+	if unit.Package != nil {
+		c.checkAnnotationList(unit.Package.Annotations, unit.Package)
+	}
+	if unit.Module != nil {
+		c.checkAnnotationList(unit.Module.Annotations, unit.Module)
+	}
+	Inspect(unit, func(node Node) bool {
+		switch n := node.(type) {
+		case *Annotation:
+			c.checkAnnotation(n)
+		case *ClassDecl:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+			c.checkClassAnnotations(n)
+		case *MethodDecl:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+		case *FieldDecl:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+		case *Param:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+		case *LocalVarDecl:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+		case *ForEachStmt:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+		case *CatchClause:
+			c.checkAnnotationList(n.Modifiers.Annotations, n)
+		case *EnumConstant:
+			c.checkAnnotationList(n.Annotations, n)
+		case *TypeParam:
+			c.checkAnnotationList(n.Annotations, n)
+		case *TypeNode:
+			c.checkAnnotationList(n.Annotations, n)
+			for _, annotations := range n.DimAnnotations {
+				c.checkAnnotationList(annotations, n)
+			}
+		case *VarDeclarator:
+			for _, annotations := range n.DimAnnotations {
+				c.checkAnnotationList(annotations, n)
+			}
+		case *NewExpr:
+			// Los métodos de una clase anónima sobrescriben los de su tipo
+			if decl := c.lookupType(n.Type.Name); decl != nil && n.Body != nil {
+				inherited, complete := c.inheritedMethods(decl)
+				c.addOwnMethods(decl, inherited)
+				c.checkOverrides(n.Body, inherited, complete)
+			}
+		}
+		return true
+	})
+}
+
+// checkAnnotationList comprueba las anotaciones de un mismo elemento: que no
+// se repitan y que puedan aplicarse a él
+func (c *checker) checkAnnotationList(annotations []*Annotation, target Node) {
+	seen := make(map[string]*Annotation)
+	for _, annotation := range annotations {
+		name := simpleTypeName(annotation.Name)
+		if first, repeated := seen[name]; repeated && !c.isRepeatable(name) {
+			c.errorf(annotation, "SEM095", name).addLabel(first, "SEM095.first")
+		} else if !repeated {
+			seen[name] = annotation
+		}
+		c.checkAnnotationTarget(annotation, target)
+	}
+}
+
+// isRepeatable indica si un tipo de anotación declarado en el código lleva
+// @Repeatable. Las anotaciones de la biblioteca no se repiten.
+func (c *checker) isRepeatable(name string) bool {
+	decl := c.lookupType(name)
+	return decl != nil && decl.Modifiers.Annotation("Repeatable") != nil
+}
+
+// checkAnnotationTarget comprueba que las anotaciones de java.lang con
+// reglas propias se apliquen al elemento adecuado
+func (c *checker) checkAnnotationTarget(annotation *Annotation, target Node) {
+	method, isMethod := target.(*MethodDecl)
+	switch simpleTypeName(annotation.Name) {
+	case "Override":
+		switch {
+		case !isMethod || method.IsConstructor():
+			c.errorf(annotation, "SEM096")
+		case method.Modifiers.Has("static"):
+			c.errorf(annotation, "SEM097", method.Name)
+		}
+	case "FunctionalInterface":
+		if decl, ok := target.(*ClassDecl); !ok {
+			c.errorf(annotation, "SEM098")
+		} else if decl.Kind != "interface" {
+			c.errorf(annotation, "SEM099", decl.Name, describeTypeKind(decl))
+		}
+	case "SafeVarargs":
+		if !isMethod {
+			c.errorf(annotation, "SEM100")
+			break
+		}
+		varargs := len(method.Params) > 0 && method.Params[len(method.Params)-1].Varargs
+		overridable := !method.IsConstructor() && !method.Modifiers.Has("static") &&
+			!method.Modifiers.Has("final") && !method.Modifiers.Has("private")
+		switch {
+		case !varargs:
+			c.errorf(annotation, "SEM101", method.Name)
+		case overridable:
+			c.errorf(annotation, "SEM102", method.Name)
+		}
+	}
+}
+
+// checkAnnotation comprueba los valores de una anotación cuyo tipo está
+// declarado en el código: que los elementos existan, que sus valores tengan
+// el tipo adecuado y que no falte ninguno obligatorio
+func (c *checker) checkAnnotation(annotation *Annotation) {
+	decl := c.lookupType(annotation.Name)
+	if decl == nil {
+		return
+	}
+	if decl.Kind != "@interface" {
+		c.errorf(annotation, "SEM103", decl.Name, describeTypeKind(decl))
+		return
+	}
+
+	elements := make(map[string]*MethodDecl)
+	for _, member := range decl.Members {
+		if method, ok := member.(*MethodDecl); ok {
+			elements[method.Name] = method
+		}
+	}
+	given := make(map[string]bool)
+	for _, arg := range annotation.Args {
+		name := arg.ElementName()
+		if given[name] {
+			c.errorf(arg, "SEM104", name, decl.Name)
+		}
+		given[name] = true
+		element, ok := elements[name]
+		if !ok {
+			c.errorf(arg, "SEM105", decl.Name, name)
+			continue
+		}
+		c.checkElementValue(arg.Value, typeName(element.ResultType, 0), name)
+	}
+	for _, member := range decl.Members {
+		if element, ok := member.(*MethodDecl); ok && element.Default == nil && !given[element.Name] {
+			c.errorf(annotation, "SEM106", element.Name, decl.Name)
+		}
+	}
+}
+
+// checkElementValue comprueba que value pueda asignarse al elemento name de
+// tipo typ. Un valor suelto vale también para un elemento de tipo array.
+func (c *checker) checkElementValue(value Expr, typ, name string) {
+	if list, ok := value.(*ArrayInitializer); ok {
+		if typ != typeUnknown && !isArrayType(typ) {
+			c.errorf(list, "SEM107", name, typ)
+			return
+		}
+		for _, elem := range list.Elems {
+			c.checkElementValue(elem, elementType(typ), name)
+		}
+		return
+	}
+	if isArrayType(typ) {
+		typ = elementType(typ)
+	}
+	valueType := c.elementValueType(value)
+	if !isAssignableType(rawType(typ), rawType(valueType), constantValue(value)) {
+		c.errorf(value, "SEM108", valueType, typ, name)
+	}
+}
+
+// elementValueType devuelve el tipo de un valor de anotación. Solo se
+// deducen los valores que no dependen de las variables en ámbito.
+func (c *checker) elementValueType(value Expr) string {
+	switch v := unparen(value).(type) {
+	case *Literal:
+		return literalType(v.Kind)
+	case *ClassLiteral:
+		return "Class"
+	case *Annotation:
+		return simpleTypeName(v.Name)
+	case *FieldAccess:
+		// Constante de un enum declarado: Nivel.ALTO
+		if id, ok := v.X.(*Ident); ok && c.lookupType(id.Name) != nil {
+			typ, _ := c.memberType(id.Name, v.Name)
+			return typ
+		}
+	}
+	return typeUnknown
+}
+
+// isAnnotationElementType indica si un elemento de un @interface puede ser
+// de tipo typ: un primitivo, String, Class, un enum, una anotación o un
+// array de una dimensión de cualquiera de ellos
+func (c *checker) isAnnotationElementType(typ string) bool {
+	if isArrayType(typ) {
+		typ = elementType(typ)
+	}
+	if isPrimitiveType(typ) || typ == "String" || rawType(typ) == "Class" || typ == typeUnknown {
+		return true
+	}
+	if decl := c.lookupType(typ); decl != nil {
+		return decl.Kind == "enum" || decl.Kind == "@interface"
+	}
+	// Los tipos de la biblioteca que no se conocen pueden ser enums
+	return typ != "Object" && !isKnownReferenceType(typ) && !isKnownGenericType(typ)
+}
+
+// isKnownGenericType indica si el tipo es una colección de la JDK
+func isKnownGenericType(typ string) bool {
+	_, ok := genericCollections[rawType(typ)]
+	return ok
+}
+
+// checkClassAnnotations comprueba las reglas de un tipo que dependen de sus
+// miembros: los elementos de un @interface, @FunctionalInterface y los
+// @Override de sus métodos y de los cuerpos de sus constantes
+func (c *checker) checkClassAnnotations(decl *ClassDecl) {
+	if decl.Kind == "@interface" {
+		for _, member := range decl.Members {
+			element, ok := member.(*MethodDecl)
+			if !ok || element.IsConstructor() {
+				continue
+			}
+			typ := typeName(element.ResultType, 0)
+			if !c.isAnnotationElementType(typ) {
+				c.errorf(element, "SEM109", element.Name, decl.Name, typ)
+			} else if element.Default != nil {
+				c.checkElementValue(element.Default, typ, element.Name)
+			}
+		}
+	}
+	if decl.Kind == "interface" && decl.Modifiers.Annotation("FunctionalInterface") != nil {
+		c.checkFunctionalInterface(decl)
+	}
+
+	inherited, complete := c.inheritedMethods(decl)
+	if decl.Kind == "record" {
+		// Los accesores explícitos de un record sobrescriben los implícitos
+		for _, component := range decl.Components {
+			inherited[component.Name+"/0"] = true
+		}
+	}
+	c.checkOverrides(decl.Members, inherited, complete)
+	if len(decl.Constants) > 0 {
+		// El cuerpo de una constante es una subclase anónima del enum
+		own := make(map[string]bool)
+		for sig := range inherited {
+			own[sig] = true
+		}
+		c.addOwnMethods(decl, own)
+		for _, constant := range decl.Constants {
+			c.checkOverrides(constant.Body, own, complete)
+		}
+	}
+}
+
+// checkOverrides comprueba que los métodos con @Override sobrescriban alguno
+// de los métodos heredados. Si la jerarquía no se conoce entera no se
+// reporta nada.
+func (c *checker) checkOverrides(members []Decl, inherited map[string]bool, complete bool) {
+	if !complete {
+		return
+	}
+	for _, member := range members {
+		method, ok := member.(*MethodDecl)
+		if !ok || method.IsConstructor() || method.Modifiers.Has("static") || method.Modifiers.Annotation("Override") == nil {
+			continue
+		}
+		if !inherited[methodSignature(method)] {
+			c.errorf(method, "SEM110", method.Name)
+		}
+	}
+}
+
+// inheritedMethods devuelve los métodos que decl hereda de sus supertipos
+// y de Object. complete es false si algún supertipo no está declarado en el
+// código.
+func (c *checker) inheritedMethods(decl *ClassDecl) (map[string]bool, bool) {
+	methods := make(map[string]bool)
+	for sig := range objectMethods {
+		methods[sig] = true
+	}
+	complete := c.collectInherited(decl, methods, 0)
+	return methods, complete
+}
+
+// collectInherited añade a methods los métodos de los supertipos de decl
+func (c *checker) collectInherited(decl *ClassDecl, methods map[string]bool, depth int) bool {
+	if depth > 32 {
+		return true // jerarquía cíclica, ya se reporta en otro sitio
+	}
+	complete := true
+	for _, super := range c.supertypes(decl) {
+		parent := c.lookupType(super.Name)
+		if parent == nil {
+			complete = false
+			continue
+		}
+		c.addOwnMethods(parent, methods)
+		if !c.collectInherited(parent, methods, depth+1) {
+			complete = false
+		}
+	}
+	return complete
+}
+
+// addOwnMethods añade a methods los métodos de instancia que declara decl y
+// que sus subtipos pueden sobrescribir
+func (c *checker) addOwnMethods(decl *ClassDecl, methods map[string]bool) {
+	for _, member := range decl.Members {
+		method, ok := member.(*MethodDecl)
+		if !ok || method.IsConstructor() || method.Modifiers.Has("static") || method.Modifiers.Has("private") {
+			continue
+		}
+		methods[methodSignature(method)] = true
+	}
+	for _, component := range decl.Components {
+		methods[component.Name+"/0"] = true
+	}
+}
+
+// checkFunctionalInterface comprueba que una interfaz con
+// @FunctionalInterface tenga exactamente un método abstracto, contando los
+// heredados. Los métodos públicos de Object no cuentan.
+func (c *checker) checkFunctionalInterface(decl *ClassDecl) {
+	abstract, complete := c.abstractMethods(decl)
+	switch {
+	case len(abstract) == 0 && complete:
+		c.errorf(decl, "SEM111", decl.Name)
+	case len(abstract) > 1:
+		c.errorf(decl, "SEM112", decl.Name, len(abstract))
+	}
+}
+
+// abstractMethods devuelve los métodos abstractos de una interfaz, contando
+// los heredados y sin contar los métodos públicos de Object. complete es
+// false si alguna superinterfaz no está declarada en el código.
+func (c *checker) abstractMethods(decl *ClassDecl) (map[string]*MethodDecl, bool) {
+	abstract := make(map[string]*MethodDecl)
+	complete := c.collectAbstract(decl, abstract, 0)
+	for sig := range abstract {
+		if sig == "equals/1" || sig == "hashCode/0" || sig == "toString/0" {
+			delete(abstract, sig)
+		}
+	}
+	return abstract, complete
+}
+
+// collectAbstract añade a abstract los métodos abstractos de una interfaz y
+// de sus superinterfaces, salvo los que la propia interfaz implementa con
+// un método default
+func (c *checker) collectAbstract(decl *ClassDecl, abstract map[string]*MethodDecl, depth int) bool {
+	complete := true
+	if depth <= 32 {
+		for _, super := range decl.Extends {
+			parent := c.lookupType(super.Name)
+			if parent == nil {
+				complete = false
+				continue
+			}
+			if !c.collectAbstract(parent, abstract, depth+1) {
+				complete = false
+			}
+		}
+	}
+	for _, member := range decl.Members {
+		method, ok := member.(*MethodDecl)
+		if !ok || method.Modifiers.Has("static") || method.Modifiers.Has("private") {
+			continue
+		}
+		if method.Body == nil {
+			abstract[methodSignature(method)] = method
+		} else {
+			delete(abstract, methodSignature(method))
+		}
+	}
+	return complete
+}
@@ -0,0 +1,674 @@
+// analyzer/ast.go
+package analyzer
+
+// Span delimita un nodo del árbol en el código fuente. EndLine y EndCol
+// indican la posición siguiente al último carácter del nodo.
+type Span struct {
+	Line, Col             int
+	EndLine, EndCol       int
+	Offset, EndOffset     int
+	Offset16, EndOffset16 int
+}
+
+// Range devuelve la posición del nodo
+func (s Span) Range() Span {
+	return s
+}
+
+// spanBetween devuelve la posición que va del token first al token last
+func spanBetween(first, last Token) Span {
+	return Span{
+		Line: first.Line, Col: first.Col,
+		EndLine: last.EndLine, EndCol: last.EndCol,
+		Offset: first.Offset, EndOffset: last.EndOffset,
+		Offset16: first.Offset16, EndOffset16: last.EndOffset16,
+	}
+}
+
+// tokenSpan devuelve la posición de un único token
+func tokenSpan(token Token) Span {
+	return spanBetween(token, token)
+}
+
+// Node nodo del árbol sintáctico
+type Node interface {
+	Range() Span
+}
+
+// Decl declaración: tipo, método, campo o constructor
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Stmt sentencia
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// Expr expresión
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// CompilationUnit raíz del árbol: un archivo Java completo o un fragmento
+// con métodos y sentencias sueltas, como los ejercicios del curso
+type CompilationUnit struct {
+	Span
+	Package    *PackageDecl
+	Imports    []*ImportDecl
+	Module     *ModuleDecl // solo en module-info.java
+	Types      []*ClassDecl
+	Methods    []*MethodDecl // métodos fuera de una clase (fragmentos)
+	Statements []Stmt        // sentencias fuera de una clase (fragmentos)
+}
+
+// PackageDecl declaración "package a.b.c;"
+type PackageDecl struct {
+	Span
+	Annotations []*Annotation
+	Name        string
+}
+
+// ImportDecl declaración "import a.b.C;", "import a.b.*;" o
+// "import static a.b.C.m;"
+type ImportDecl struct {
+	Span
+	Name     string
+	Static   bool
+	OnDemand bool // termina en ".*"
+}
+
+// ModuleDecl declaración de un módulo en module-info.java
+type ModuleDecl struct {
+	Span
+	Annotations []*Annotation
+	Open        bool
+	Name        string
+	Directives  []*ModuleDirective
+}
+
+// ModuleDirective directiva de un módulo: "requires transitive a.b;",
+// "exports p to m1, m2;", "provides S with I;"...
+type ModuleDirective struct {
+	Span
+	Kind      string    // "requires", "exports", "opens", "uses" o "provides"
+	Modifiers Modifiers // "transitive" y "static" en requires
+	Name      string
+	Targets   []string // módulos después de "to" o implementaciones después de "with"
+}
+
+// TypeNode referencia a un tipo: primitivo, clase, array, tipo genérico
+// ("List<String>") o comodín ("? extends Number")
+type TypeNode struct {
+	Span
+	Name string      // nombre tal y como aparece, p. ej. "int" o "java.util.List"; "?" en un comodín
+	Args []*TypeNode // argumentos genéricos
+	Dims int         // dimensiones de array
+	// Diamond indica "<>" en "new ArrayList<>()"
+	Diamond bool
+	// TypeVar indica que el nombre es un parámetro de tipo en ámbito, como T
+	TypeVar bool
+	// Annotations anotaciones de uso de tipo: "@NonNull String"
+	Annotations []*Annotation
+	// DimAnnotations anotaciones de cada dimensión: "String @NonNull []";
+	// nil si ninguna dimensión lleva anotaciones
+	DimAnnotations [][]*Annotation
+	// Bound es el límite de un comodín; Super indica "? super" en lugar de "? extends"
+	Bound *TypeNode
+	Super bool
+}
+
+// TypeParam parámetro de tipo de una clase o método: "T extends A & B"
+type TypeParam struct {
+	Span
+	Annotations []*Annotation
+	Name        string
+	Bounds      []*TypeNode
+}
+
+// Modifiers modificadores de una declaración ("public", "static", ...) y
+// las anotaciones que los acompañan
+type Modifiers struct {
+	Keywords    []string
+	Annotations []*Annotation
+}
+
+// Has indica si el modificador está presente
+func (m Modifiers) Has(name string) bool {
+	for _, modifier := range m.Keywords {
+		if modifier == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Annotation busca una anotación por su nombre simple
+func (m Modifiers) Annotation(name string) *Annotation {
+	return findAnnotation(m.Annotations, name)
+}
+
+// findAnnotation busca una anotación por su nombre simple: "Override" también
+// encuentra "@java.lang.Override"
+func findAnnotation(annotations []*Annotation, name string) *Annotation {
+	for _, annotation := range annotations {
+		if simpleTypeName(annotation.Name) == name {
+			return annotation
+		}
+	}
+	return nil
+}
+
+// ClassDecl declaración de una clase, interfaz, enum o record
+type ClassDecl struct {
+	Span
+	Modifiers  Modifiers
+	Kind       string // "class", "interface", "@interface", "enum" o "record"
+	Name       string
+	TypeParams []*TypeParam
+	Components []*Param // componentes de un record
+	Extends    []*TypeNode
+	Implements []*TypeNode
+	Permits    []*TypeNode     // subtipos permitidos de un tipo sealed
+	Constants  []*EnumConstant // constantes de un enum
+	Members    []Decl
+}
+
+// EnumConstant constante de un enum, con sus argumentos y su cuerpo opcionales
+type EnumConstant struct {
+	Span
+	Annotations []*Annotation
+	Name        string
+	Args        []Expr
+	Body        []Decl // nil si no tiene cuerpo
+}
+
+// InitializerBlock bloque inicializador "{ ... }" o "static { ... }"
+type InitializerBlock struct {
+	Span
+	Static bool
+	Body   *Block
+}
+
+// FieldDecl declaración de uno o varios campos
+type FieldDecl struct {
+	Span
+	Modifiers Modifiers
+	Type      *TypeNode
+	Vars      []*VarDeclarator
+}
+
+// MethodDecl declaración de un método o de un constructor
+type MethodDecl struct {
+	Span
+	Modifiers  Modifiers
+	TypeParams []*TypeParam
+	ResultType *TypeNode // nil en los constructores
+	Name       string
+	Params     []*Param
+	Throws     []*TypeNode
+	Body       *Block // nil en los métodos abstractos
+	// Compact indica un constructor compacto de record, sin lista de parámetros
+	Compact bool
+	// Default valor por defecto de un elemento de un @interface
+	Default Expr
+}
+
+// IsInterface indica si el tipo es una interfaz o un tipo de anotación
+func (d *ClassDecl) IsInterface() bool {
+	return d.Kind == "interface" || d.Kind == "@interface"
+}
+
+// keyword devuelve "this" o "super"
+func (c *ConstructorCall) keyword() string {
+	if c.Super {
+		return "super"
+	}
+	return "this"
+}
+
+// IsConstructor indica si la declaración es un constructor
+func (m *MethodDecl) IsConstructor() bool {
+	return m.ResultType == nil
+}
+
+// Param parámetro formal de un método
+type Param struct {
+	Span
+	Modifiers Modifiers
+	Type      *TypeNode
+	Name      string
+	Varargs   bool
+}
+
+// VarDeclarator variable dentro de una declaración: "x = 5" en "int x = 5, y;"
+type VarDeclarator struct {
+	Span
+	Name string
+	Dims int // dimensiones al estilo C: "int a[]"
+	// DimAnnotations anotaciones de cada dimensión, como en TypeNode
+	DimAnnotations [][]*Annotation
+	Init           Expr // nil si no se inicializa
+}
+
+// Block bloque de sentencias entre llaves
+type Block struct {
+	Span
+	Stmts []Stmt
+}
+
+// LocalVarDecl declaración de variables locales
+type LocalVarDecl struct {
+	Span
+	Modifiers Modifiers
+	Type      *TypeNode
+	Vars      []*VarDeclarator
+}
+
+// LocalClassStmt declaración de una clase, interfaz, enum o record local
+type LocalClassStmt struct {
+	Span
+	Decl *ClassDecl
+}
+
+// ExprStmt expresión usada como sentencia
+type ExprStmt struct {
+	Span
+	X Expr
+}
+
+// IfStmt sentencia if con su else opcional
+type IfStmt struct {
+	Span
+	Cond Expr
+	Then Stmt
+	Else Stmt // nil si no hay else
+}
+
+// WhileStmt bucle while
+type WhileStmt struct {
+	Span
+	Cond Expr
+	Body Stmt
+}
+
+// DoStmt bucle do-while
+type DoStmt struct {
+	Span
+	Body Stmt
+	Cond Expr
+}
+
+// ForStmt bucle for clásico
+type ForStmt struct {
+	Span
+	Init   []Stmt // declaración local o expresiones
+	Cond   Expr   // nil si se omite
+	Update []Expr
+	Body   Stmt
+}
+
+// ForEachStmt bucle for-each: "for (T x : expresión) cuerpo"
+type ForEachStmt struct {
+	Span
+	Modifiers Modifiers
+	VarType   *TypeNode
+	Name      string
+	Iterable  Expr
+	Body      Stmt
+}
+
+// LabeledStmt sentencia con etiqueta: "externo: for (...) ..."
+type LabeledStmt struct {
+	Span
+	Label string
+	Body  Stmt
+}
+
+// SwitchStmt sentencia switch
+type SwitchStmt struct {
+	Span
+	Selector Expr
+	Cases    []*SwitchCase
+}
+
+// SwitchCase rama "case" o "default" de un switch
+type SwitchCase struct {
+	Span
+	Labels  []Expr   // constantes de "case A, B", incluido null
+	Pattern *Pattern // patrón de "case T t", nil si no hay
+	Guard   Expr     // condición "when", nil si no hay
+	Default bool     // "default" o "case null, default"
+	Arrow   bool     // forma "case X ->"
+	// Body sentencias de la rama. En la forma con flecha contiene un único
+	// bloque, throw o expresión.
+	Body []Stmt
+}
+
+// Pattern patrón de tipo "T t" o de record "Punto(int x, int y)"
+type Pattern struct {
+	Span
+	Type       *TypeNode
+	Name       string     // vacío en los patrones de record sin nombre
+	Components []*Pattern // componentes de un patrón de record, nil si no lo es
+	Record     bool
+}
+
+// YieldStmt sentencia "yield valor;" de una expresión switch
+type YieldStmt struct {
+	Span
+	Value Expr
+}
+
+// ReturnStmt sentencia return
+type ReturnStmt struct {
+	Span
+	Value Expr // nil en "return;"
+}
+
+// BreakStmt sentencia break
+type BreakStmt struct {
+	Span
+	Label string
+}
+
+// ContinueStmt sentencia continue
+type ContinueStmt struct {
+	Span
+	Label string
+}
+
+// TryStmt sentencia try con sus catch y su finally
+type TryStmt struct {
+	Span
+	// Resources recursos de try-with-resources: declaraciones (*LocalVarDecl)
+	// o variables existentes (Expr)
+	Resources []Node
+	Body      *Block
+	Catches   []*CatchClause
+	Finally   *Block // nil si no hay finally
+}
+
+// CatchClause bloque "catch (A | B e) { ... }"
+type CatchClause struct {
+	Span
+	Modifiers Modifiers
+	Types     []*TypeNode // varias en un multi-catch
+	Name      string
+	Body      *Block
+}
+
+// ThrowStmt sentencia throw
+type ThrowStmt struct {
+	Span
+	Value Expr
+}
+
+// AssertStmt sentencia "assert cond;" o "assert cond : mensaje;"
+type AssertStmt struct {
+	Span
+	Cond    Expr
+	Message Expr // nil si no hay mensaje
+}
+
+// SynchronizedStmt bloque "synchronized (objeto) { ... }"
+type SynchronizedStmt struct {
+	Span
+	Lock Expr
+	Body *Block
+}
+
+// EmptyStmt sentencia vacía ";"
+type EmptyStmt struct {
+	Span
+}
+
+// Ident nombre simple
+type Ident struct {
+	Span
+	Name string
+}
+
+// Literal literal numérico, de texto, de carácter, booleano o null. Kind es
+// KindError si el lexer lo reconoció mal formado.
+type Literal struct {
+	Span
+	Kind  TokenKind
+	Value string
+	Raw   string
+}
+
+// ParenExpr expresión entre paréntesis
+type ParenExpr struct {
+	Span
+	X Expr
+}
+
+// UnaryExpr operación unaria prefija o postfija
+type UnaryExpr struct {
+	Span
+	Op      TokenKind
+	X       Expr
+	Postfix bool
+}
+
+// BinaryExpr operación binaria
+type BinaryExpr struct {
+	Span
+	Op   TokenKind
+	X, Y Expr
+}
+
+// AssignExpr asignación simple o compuesta
+type AssignExpr struct {
+	Span
+	Op     TokenKind
+	Target Expr
+	Value  Expr
+}
+
+// FieldAccess acceso a un campo: "x.name"
+type FieldAccess struct {
+	Span
+	X    Expr
+	Name string
+}
+
+// MethodCall llamada a un método. Target es nil si no está calificada.
+type MethodCall struct {
+	Span
+	Target   Expr
+	TypeArgs []*TypeNode // argumentos genéricos explícitos: Collections.<String>emptyList()
+	Name     string
+	Args     []Expr
+}
+
+// ThisExpr referencia "this"
+type ThisExpr struct {
+	Span
+}
+
+// SuperExpr referencia "super"
+type SuperExpr struct {
+	Span
+}
+
+// ConstructorCall llamada explícita a otro constructor de la misma clase,
+// "this(...)", o de la superclase, "super(...)"
+type ConstructorCall struct {
+	Span
+	Super bool
+	Args  []Expr
+}
+
+// ConditionalExpr operador condicional "c ? a : b"
+type ConditionalExpr struct {
+	Span
+	Cond, Then, Else Expr
+}
+
+// CastExpr conversión de tipo "(T) x"
+type CastExpr struct {
+	Span
+	Type *TypeNode
+	X    Expr
+}
+
+// InstanceOfExpr comprobación "x instanceof T", con variable de patrón
+// opcional: "x instanceof T t"
+type InstanceOfExpr struct {
+	Span
+	X       Expr
+	Type    *TypeNode
+	Binding string
+}
+
+// SwitchExpr expresión switch, que produce un valor
+type SwitchExpr struct {
+	Span
+	Selector Expr
+	Cases    []*SwitchCase
+}
+
+// ArrayAccess acceso a un elemento: "a[i]"
+type ArrayAccess struct {
+	Span
+	X     Expr
+	Index Expr
+}
+
+// NewExpr creación de una instancia: "new T(args)", con el cuerpo de la
+// clase anónima si lo hay
+type NewExpr struct {
+	Span
+	// Outer instancia que encierra a la clase interna en "outer.new Inner()";
+	// nil si la creación no está calificada
+	Outer Expr
+	Type  *TypeNode
+	Args  []Expr
+	Body  []Decl
+}
+
+// Annotation anotación: "@Override", "@SuppressWarnings("unchecked")" o
+// "@Size(min = 1, max = 10)". Es una expresión porque puede ser el valor de
+// otra anotación.
+type Annotation struct {
+	Span
+	Name string
+	// Args valores entre paréntesis; nil si la anotación no los lleva
+	Args []*AnnotationArg
+}
+
+// AnnotationArg valor de una anotación, con nombre ("max = 10") o sin él,
+// que equivale a "value = ..."
+type AnnotationArg struct {
+	Span
+	Name  string
+	Value Expr // expresión, anotación o lista de valores entre llaves
+}
+
+// ElementName devuelve el nombre del elemento al que se asigna el valor
+func (a *AnnotationArg) ElementName() string {
+	if a.Name == "" {
+		return "value"
+	}
+	return a.Name
+}
+
+// ErrorNode ocupa el lugar de una sentencia, declaración o expresión que no
+// pudo analizarse; Span abarca los tokens descartados al recuperarse del error
+type ErrorNode struct {
+	Span
+}
+
+// NewArrayExpr creación de un array: "new int[5][]" o "new int[]{1, 2}"
+type NewArrayExpr struct {
+	Span
+	Type    *TypeNode // tipo del array creado, con todas sus dimensiones
+	Lengths []Expr    // tamaños de las primeras dimensiones
+	Init    *ArrayInitializer
+}
+
+// ArrayInitializer lista de valores de un array: "{1, 2, 3}"
+type ArrayInitializer struct {
+	Span
+	Elems []Expr
+}
+
+// ClassLiteral literal de clase: "String.class"
+type ClassLiteral struct {
+	Span
+	Type *TypeNode
+}
+
+// LambdaExpr expresión lambda. Los parámetros sin tipo explícito tienen
+// Type nil. Body es una Expr o un *Block.
+type LambdaExpr struct {
+	Span
+	Params []*Param
+	Body   Node
+}
+
+// MethodRef referencia a método: "String::valueOf", "this::f", "T::new"
+type MethodRef struct {
+	Span
+	X    Expr
+	Type *TypeNode // en lugar de X, un tipo genérico: "ArrayList<String>::new"
+	Name string    // "new" para las referencias a constructor
+}
+
+func (*ClassDecl) declNode()        {}
+func (*FieldDecl) declNode()        {}
+func (*MethodDecl) declNode()       {}
+func (*InitializerBlock) declNode() {}
+func (*ErrorNode) declNode()        {}
+
+func (*Block) stmtNode()            {}
+func (*LocalVarDecl) stmtNode()     {}
+func (*LocalClassStmt) stmtNode()   {}
+func (*ExprStmt) stmtNode()         {}
+func (*IfStmt) stmtNode()           {}
+func (*WhileStmt) stmtNode()        {}
+func (*DoStmt) stmtNode()           {}
+func (*ForStmt) stmtNode()          {}
+func (*ForEachStmt) stmtNode()      {}
+func (*LabeledStmt) stmtNode()      {}
+func (*SwitchStmt) stmtNode()       {}
+func (*YieldStmt) stmtNode()        {}
+func (*ReturnStmt) stmtNode()       {}
+func (*BreakStmt) stmtNode()        {}
+func (*ContinueStmt) stmtNode()     {}
+func (*TryStmt) stmtNode()          {}
+func (*ThrowStmt) stmtNode()        {}
+func (*AssertStmt) stmtNode()       {}
+func (*SynchronizedStmt) stmtNode() {}
+func (*EmptyStmt) stmtNode()        {}
+func (*ErrorNode) stmtNode()        {}
+
+func (*Ident) exprNode()            {}
+func (*Literal) exprNode()          {}
+func (*ParenExpr) exprNode()        {}
+func (*UnaryExpr) exprNode()        {}
+func (*BinaryExpr) exprNode()       {}
+func (*AssignExpr) exprNode()       {}
+func (*FieldAccess) exprNode()      {}
+func (*MethodCall) exprNode()       {}
+func (*ThisExpr) exprNode()         {}
+func (*SuperExpr) exprNode()        {}
+func (*ConstructorCall) exprNode()  {}
+func (*ConditionalExpr) exprNode()  {}
+func (*CastExpr) exprNode()         {}
+func (*InstanceOfExpr) exprNode()   {}
+func (*SwitchExpr) exprNode()       {}
+func (*ArrayAccess) exprNode()      {}
+func (*NewExpr) exprNode()          {}
+func (*ClassLiteral) exprNode()     {}
+func (*LambdaExpr) exprNode()       {}
+func (*MethodRef) exprNode()        {}
+func (*Annotation) exprNode()       {}
+func (*NewArrayExpr) exprNode()     {}
+func (*ArrayInitializer) exprNode() {}
+func (*ErrorNode) exprNode()        {}
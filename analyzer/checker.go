@@ -0,0 +1,1168 @@
+// analyzer/checker.go
+package analyzer
+
+import "strings"
+
+// symbol variable, parámetro o campo visible en un ámbito
+type symbol struct {
+	name string
+	typ  string
+	// pos declaración de la variable
+	pos Span
+	// final indica que la variable ya tiene valor y no admite asignaciones
+	final bool
+	// assigned indica que la variable se modificó después de declararse
+	assigned bool
+}
+
+// scope ámbito de nombres. Los ámbitos de clase contienen campos, que las
+// variables locales pueden ocultar; los demás contienen variables locales,
+// que no pueden redeclararse en un ámbito anidado del mismo método.
+type scope struct {
+	parent *scope
+	vars   map[string]*symbol
+	class  bool
+}
+
+func newScope(parent *scope, class bool) *scope {
+	return &scope{parent: parent, vars: make(map[string]*symbol), class: class}
+}
+
+// lookup busca un nombre en el ámbito y en los que lo contienen
+func (s *scope) lookup(name string) *symbol {
+	for ; s != nil; s = s.parent {
+		if sym, ok := s.vars[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// lookupLocal busca un nombre sin salir del método actual
+func (s *scope) lookupLocal(name string) *symbol {
+	for ; s != nil && !s.class; s = s.parent {
+		if sym, ok := s.vars[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+// label etiqueta de sentencia visible en el método actual
+type label struct {
+	name string
+	loop bool // etiqueta un bucle, así que admite continue
+}
+
+// flowContext estado del flujo de control dentro de un método o lambda
+type flowContext struct {
+	loops     int // bucles que contienen la sentencia actual
+	breakable int // bucles y switch que contienen la sentencia actual
+	labels    []label
+	// returnType tipo de retorno del método, "void" en los constructores y
+	// desconocido fuera de un método
+	returnType string
+	// yields tipos de los valores producidos por la rama de la expresión
+	// switch actual; nil fuera de una expresión switch
+	yields *[]string
+	// lambda interfaz funcional de la lambda cuyo cuerpo se analiza; vacío
+	// en el cuerpo de un método
+	lambda string
+}
+
+// checker analizador semántico sobre el árbol sintáctico: resuelve nombres,
+// calcula el tipo de cada expresión y comprueba su compatibilidad
+type checker struct {
+	errors   []Diagnostic
+	warnings []Diagnostic
+	scope    *scope
+	// types clases e interfaces declaradas en la unidad
+	types map[string]*ClassDecl
+	// methods tipo de retorno de los métodos declarados en la unidad
+	methods map[string]string
+	// methodDecls métodos declarados en la unidad por nombre, para dar tipo a
+	// las lambdas que se pasan como argumento
+	methodDecls map[string][]*MethodDecl
+	flow        flowContext
+	// className clase cuyo cuerpo se está analizando
+	className string
+	// inheritsUnknown indica que la clase actual extiende una clase
+	// desconocida, cuyos campos heredados no pueden resolverse
+	inheritsUnknown bool
+	// stringMethods, si no es nil, valida los métodos llamados sobre String
+	stringMethods *StringLibrary
+	// declared todas las variables declaradas, por nombre
+	declared map[string]Variable
+	// resources variables existentes usadas como recurso de un try, que
+	// deben ser efectivamente finales en todo el método
+	resources []resourceUse
+	// imported paquete e imports de la unidad
+	imported importScope
+	// docs comentarios Javadoc de la unidad; sus referencias también
+	// cuentan como uso de los imports
+	docs []*DocComment
+}
+
+func newChecker() *checker {
+	return &checker{
+		types:       make(map[string]*ClassDecl),
+		methods:     make(map[string]string),
+		methodDecls: make(map[string][]*MethodDecl),
+		declared:    make(map[string]Variable),
+	}
+}
+
+// checkUnit analiza una unidad de compilación completa
+func (c *checker) checkUnit(unit *CompilationUnit) {
+	c.collectTypes(unit.Types)
+	c.checkImports(unit)
+	if unit.Module != nil {
+		c.checkModule(unit)
+	}
+	for _, method := range unit.Methods {
+		c.methods[method.Name] = typeName(method.ResultType, 0)
+		c.methodDecls[method.Name] = append(c.methodDecls[method.Name], method)
+	}
+
+	c.scope = newScope(nil, true)
+	// Las sentencias sueltas de un fragmento se comportan como el cuerpo de
+	// un método; los métodos sueltos ven sus variables
+	body := newScope(c.scope, false)
+	c.scope = body
+	for _, stmt := range unit.Statements {
+		c.checkStmt(stmt)
+	}
+	for _, method := range unit.Methods {
+		c.checkMethod(method)
+	}
+	c.scope = body.parent
+	for _, decl := range unit.Types {
+		c.checkClass(decl)
+	}
+
+	for _, use := range c.resources {
+		if use.sym.assigned {
+			c.errorf(use.pos, "SEM001", use.sym.name)
+		}
+	}
+	c.checkAnnotations(unit)
+	c.checkUnusedImports(unit)
+}
+
+// checkMembers analiza los miembros de una clase o de una clase anónima.
+// fields son los campos implícitos, como las constantes de un enum. Los
+// campos son visibles en toda la clase, así que se declaran antes de analizar.
+func (c *checker) checkMembers(members []Decl, fields []*symbol) {
+	c.scope = newScope(c.scope, true)
+	outerFlow := c.flow
+	c.flow = flowContext{}
+	defer func() { c.scope, c.flow = c.scope.parent, outerFlow }()
+
+	for _, sym := range fields {
+		c.scope.vars[sym.name] = sym
+	}
+	for _, member := range members {
+		if field, ok := member.(*FieldDecl); ok {
+			for _, v := range field.Vars {
+				if previous, exists := c.scope.vars[v.Name]; exists {
+					c.errorf(v, "SEM002", v.Name).addLabel(previous.pos, "SEM002.previous")
+					continue
+				}
+				c.scope.vars[v.Name] = &symbol{
+					name: v.Name, typ: typeName(field.Type, v.Dims), pos: v.Span,
+					final: field.Modifiers.Has("final") && v.Init != nil,
+				}
+			}
+		}
+	}
+	for _, member := range members {
+		switch m := member.(type) {
+		case *FieldDecl:
+			for _, v := range m.Vars {
+				if v.Init != nil {
+					c.checkInitializer(v, typeName(m.Type, v.Dims))
+				}
+			}
+		case *MethodDecl:
+			c.checkMethod(m)
+		case *InitializerBlock:
+			c.flow = flowContext{returnType: "void"}
+			c.checkStmt(m.Body)
+			c.flow = flowContext{}
+		case *ClassDecl:
+			c.checkClass(m)
+		}
+	}
+}
+
+// checkMethod analiza los parámetros y el cuerpo de un método
+func (c *checker) checkMethod(method *MethodDecl) {
+	c.scope = newScope(c.scope, false)
+	outerFlow := c.flow
+	c.flow = flowContext{returnType: "void"}
+	if !method.IsConstructor() {
+		c.flow.returnType = typeName(method.ResultType, 0)
+	}
+	defer func() { c.scope, c.flow = c.scope.parent, outerFlow }()
+	c.checkTypeParams(method.TypeParams)
+	for _, param := range method.Params {
+		typ := typeName(param.Type, 0)
+		if param.Varargs {
+			typ = typeName(param.Type, 1)
+		}
+		c.declare(param.Name, typ, param, nil).final = param.Modifiers.Has("final")
+	}
+	if record := c.lookupType(c.className); method.Compact && record != nil {
+		// Los parámetros de un constructor compacto son los componentes del record
+		for _, component := range record.Components {
+			c.declare(component.Name, typeName(component.Type, 0), component, nil)
+		}
+	}
+	if method.Body != nil {
+		stmts := method.Body.Stmts
+		if call := leadingConstructorCall(stmts); call != nil && method.IsConstructor() && !method.Compact {
+			c.checkConstructorCall(call)
+			stmts = stmts[1:]
+		}
+		c.checkStmts(stmts)
+	}
+}
+
+// declare declara una variable local, situada en at, en el ámbito actual
+func (c *checker) declare(name, typ string, at Node, init Expr) *symbol {
+	sym := &symbol{name: name, typ: typ, pos: at.Range()}
+	if previous := c.scope.lookupLocal(name); previous != nil {
+		c.errorf(at, "SEM002", name).addLabel(previous.pos, "SEM002.previous")
+		return sym
+	}
+	c.scope.vars[name] = sym
+	if _, exists := c.declared[name]; !exists {
+		c.declared[name] = Variable{Name: name, Type: typ, Value: constantInit(init), Line: sym.pos.Line}
+	}
+	return sym
+}
+
+// markAssigned registra la modificación de una variable y comprueba que no
+// sea final
+func (c *checker) markAssigned(target Expr) {
+	if field, ok := unparen(target).(*FieldAccess); ok && field.Name == "length" && isArrayType(c.typeOf(field.X)) {
+		c.errorf(field, "SEM003")
+		return
+	}
+	id, ok := unparen(target).(*Ident)
+	if !ok {
+		return
+	}
+	sym := c.scope.lookup(id.Name)
+	if sym == nil {
+		return
+	}
+	if sym.final {
+		c.errorf(id, "SEM004", id.Name)
+	}
+	sym.assigned = true
+}
+
+// declareIfBindings declara en el ámbito que contiene el if las variables de
+// patrón que siguen vivas tras él (JLS §6.3.2): las que la condición
+// introduce al ser falsa si la rama then no puede terminar normalmente, o al
+// ser verdadera si solo la rama else no puede
+func (c *checker) declareIfBindings(s *IfStmt) {
+	var bindings []*InstanceOfExpr
+	switch {
+	case s.Else == nil:
+		if completesAbruptly(s.Then) {
+			bindings = patternBindings(s.Cond, false)
+		}
+	case completesAbruptly(s.Then) && !completesAbruptly(s.Else):
+		bindings = patternBindings(s.Cond, false)
+	case !completesAbruptly(s.Then) && completesAbruptly(s.Else):
+		bindings = patternBindings(s.Cond, true)
+	}
+	for _, e := range bindings {
+		c.declare(e.Binding, typeName(e.Type, 0), e, nil)
+	}
+}
+
+// patternBindings devuelve los instanceof cuya variable de patrón introduce
+// la condición cuando es verdadera (whenTrue) o falsa (JLS §6.3.1)
+func patternBindings(cond Expr, whenTrue bool) []*InstanceOfExpr {
+	switch e := cond.(type) {
+	case *ParenExpr:
+		return patternBindings(e.X, whenTrue)
+	case *UnaryExpr:
+		if e.Op == OpNot {
+			return patternBindings(e.X, !whenTrue)
+		}
+	case *BinaryExpr:
+		if e.Op == OpAnd && whenTrue || e.Op == OpOr && !whenTrue {
+			return append(patternBindings(e.X, whenTrue), patternBindings(e.Y, whenTrue)...)
+		}
+	case *InstanceOfExpr:
+		if e.Binding != "" && whenTrue {
+			return []*InstanceOfExpr{e}
+		}
+	}
+	return nil
+}
+
+// withScope ejecuta check en un ámbito local nuevo
+func (c *checker) withScope(check func()) {
+	c.scope = newScope(c.scope, false)
+	defer func() { c.scope = c.scope.parent }()
+	check()
+}
+
+func (c *checker) checkStmts(stmts []Stmt) {
+	for _, stmt := range stmts {
+		c.checkStmt(stmt)
+	}
+}
+
+// checkStmt analiza una sentencia
+func (c *checker) checkStmt(stmt Stmt) {
+	switch s := stmt.(type) {
+	case *Block:
+		c.withScope(func() { c.checkStmts(s.Stmts) })
+	case *LocalVarDecl:
+		c.checkLocalVarDecl(s)
+	case *ExprStmt:
+		c.typeOf(s.X)
+	case *IfStmt:
+		// Las variables de patrón de la condición solo viven en el if
+		c.withScope(func() {
+			c.checkCondition(s.Cond, "if")
+			c.checkStmt(s.Then)
+			if s.Else != nil {
+				c.checkStmt(s.Else)
+			}
+		})
+		c.declareIfBindings(s)
+	case *WhileStmt:
+		c.withScope(func() {
+			c.checkCondition(s.Cond, "while")
+			c.checkLoopBody(s.Body)
+		})
+	case *DoStmt:
+		c.checkLoopBody(s.Body)
+		c.withScope(func() { c.checkCondition(s.Cond, "do-while") })
+	case *ForStmt:
+		c.withScope(func() {
+			c.checkStmts(s.Init)
+			if s.Cond != nil {
+				c.checkCondition(s.Cond, "for")
+			}
+			for _, x := range s.Update {
+				c.typeOf(x)
+			}
+			c.checkLoopBody(s.Body)
+		})
+	case *ForEachStmt:
+		c.withScope(func() { c.checkForEach(s) })
+	case *LabeledStmt:
+		c.checkLabeled(s)
+	case *BreakStmt:
+		if s.Label != "" {
+			if c.findLabel(s.Label) == nil {
+				c.errorf(s, "SEM005", s.Label)
+			}
+		} else if c.flow.breakable == 0 {
+			if c.flow.yields != nil {
+				c.errorf(s, "SEM006")
+			} else {
+				c.errorf(s, "SEM007")
+			}
+		}
+	case *ContinueStmt:
+		if s.Label != "" {
+			if target := c.findLabel(s.Label); target == nil {
+				c.errorf(s, "SEM005", s.Label)
+			} else if !target.loop {
+				c.errorf(s, "SEM008", s.Label)
+			}
+		} else if c.flow.loops == 0 {
+			if c.flow.yields != nil {
+				c.errorf(s, "SEM009")
+			} else {
+				c.errorf(s, "SEM010")
+			}
+		}
+	case *SwitchStmt:
+		c.checkSwitch(s.Selector, s.Cases, false)
+	case *TryStmt:
+		c.checkTry(s)
+	case *LocalClassStmt:
+		c.collectTypes([]*ClassDecl{s.Decl})
+		c.checkClass(s.Decl)
+	case *YieldStmt:
+		typ := c.typeOf(s.Value)
+		if c.flow.yields == nil {
+			c.errorf(s, "SEM011")
+		} else {
+			*c.flow.yields = append(*c.flow.yields, typ)
+		}
+	case *ReturnStmt:
+		c.checkReturn(s)
+	case *ThrowStmt:
+		if typ := c.typeOf(s.Value); !c.isThrowableType(typ) {
+			c.errorf(s.Value, "SEM012", typ)
+		}
+	case *AssertStmt:
+		c.withScope(func() { c.checkCondition(s.Cond, "assert") })
+		if s.Message != nil {
+			c.typeOf(s.Message)
+		}
+	case *SynchronizedStmt:
+		if typ := c.typeOf(s.Lock); isPrimitiveType(typ) || typ == typeNull {
+			c.errorf(s.Lock, "SEM013", typ)
+		}
+		c.checkStmt(s.Body)
+	}
+}
+
+// checkLoopBody analiza el cuerpo de un bucle, donde break y continue son válidos
+func (c *checker) checkLoopBody(body Stmt) {
+	c.flow.loops++
+	c.flow.breakable++
+	c.checkStmt(body)
+	c.flow.loops--
+	c.flow.breakable--
+}
+
+// checkForEach comprueba que se recorra un array o una colección y que sus
+// elementos puedan asignarse a la variable del bucle
+func (c *checker) checkForEach(loop *ForEachStmt) {
+	iterable := c.typeOf(loop.Iterable)
+	element := elementType(iterable)
+	if !isArrayType(iterable) {
+		element = iterableElementType(iterable)
+	}
+	if isPrimitiveType(iterable) || iterable == "String" || iterable == typeNull || unboxedTypes[iterable] != "" {
+		c.errorf(loop.Iterable, "SEM014", iterable)
+	}
+
+	typ := typeName(loop.VarType, 0)
+	if loop.VarType.Name == "var" {
+		typ = element
+	} else if !isAssignableType(typ, element, nil) {
+		c.errorf(loop, "SEM015", element, typ, loop.Name)
+	}
+	c.declare(loop.Name, typ, loop, nil)
+	c.checkLoopBody(loop.Body)
+}
+
+// checkLabeled analiza una sentencia etiquetada
+func (c *checker) checkLabeled(stmt *LabeledStmt) {
+	if c.findLabel(stmt.Label) != nil {
+		c.errorf(stmt, "SEM016", stmt.Label)
+	}
+	target := label{name: stmt.Label}
+	switch stmt.Body.(type) {
+	case *WhileStmt, *DoStmt, *ForStmt, *ForEachStmt:
+		target.loop = true
+	default:
+		// break con etiqueta también sale de bloques que no son bucles
+		c.flow.breakable++
+		defer func() { c.flow.breakable-- }()
+	}
+	c.flow.labels = append(c.flow.labels, target)
+	c.checkStmt(stmt.Body)
+	c.flow.labels = c.flow.labels[:len(c.flow.labels)-1]
+}
+
+// findLabel busca una etiqueta que contenga la sentencia actual
+func (c *checker) findLabel(name string) *label {
+	for i := len(c.flow.labels) - 1; i >= 0; i-- {
+		if c.flow.labels[i].name == name {
+			return &c.flow.labels[i]
+		}
+	}
+	return nil
+}
+
+// checkReturn comprueba el valor devuelto contra el tipo de retorno del método
+func (c *checker) checkReturn(stmt *ReturnStmt) {
+	if c.flow.yields != nil {
+		c.errorf(stmt, "SEM017")
+		return
+	}
+	if _, ok := stmt.Value.(*ErrorNode); ok {
+		// El parser ya reportó la expresión que falta
+		return
+	}
+	expected := c.flow.returnType
+	if stmt.Value == nil {
+		switch {
+		case expected == typeUnknown || expected == "void":
+		case c.flow.lambda != "":
+			c.errorf(stmt, "SEM018", c.flow.lambda, expected)
+		default:
+			c.errorf(stmt, "SEM019", expected)
+		}
+		return
+	}
+	typ := c.typeOfExpected(stmt.Value, expected)
+	switch {
+	case expected == "void" && c.flow.lambda != "":
+		c.errorf(stmt, "SEM020", c.flow.lambda)
+	case expected == "void":
+		c.errorf(stmt, "SEM021")
+	case c.flow.lambda != "":
+		if typ == "void" || !isAssignableType(expected, typ, constantValue(stmt.Value)) {
+			c.errorf(stmt.Value, "SEM022", typ, c.flow.lambda, expected)
+		}
+	case !isAssignableType(expected, typ, constantValue(stmt.Value)):
+		c.errorf(stmt.Value, "SEM023", typ, expected)
+	}
+}
+
+// checkLocalVarDecl declara las variables y comprueba sus inicializadores
+func (c *checker) checkLocalVarDecl(decl *LocalVarDecl) {
+	for _, v := range decl.Vars {
+		typ := typeName(decl.Type, v.Dims)
+		if decl.Type.Name == "var" {
+			switch unparen(v.Init).(type) {
+			case *ArrayInitializer:
+				c.errorf(v, "SEM024", v.Name)
+			case *LambdaExpr:
+				c.errorf(v, "SEM025", v.Name)
+			case *MethodRef:
+				c.errorf(v, "SEM026", v.Name)
+			}
+		}
+		if v.Init != nil {
+			initType := c.checkInitializer(v, typ)
+			if decl.Type.Name == "var" && initType != typeNull {
+				typ = initType
+			}
+		}
+		// Una variable final sin inicializar puede recibir su valor después
+		c.declare(v.Name, typ, v, v.Init).final = decl.Modifiers.Has("final") && v.Init != nil
+	}
+}
+
+// checkInitializer comprueba el valor inicial de una variable
+func (c *checker) checkInitializer(v *VarDeclarator, typ string) string {
+	if init, ok := v.Init.(*ArrayInitializer); ok {
+		if typ != typeUnknown && !isArrayType(typ) {
+			c.errorf(v, "SEM027", typ, v.Name)
+			typ = typeUnknown
+		}
+		c.checkArrayInitializer(init, typ)
+		return typ
+	}
+	initType := c.typeOfExpected(v.Init, typ)
+	c.checkAssignment(typ, v.Name, v.Init, initType)
+	return initType
+}
+
+// checkArrayInitializer comprueba que cada valor de un inicializador pueda
+// guardarse en un array de tipo arrayType
+func (c *checker) checkArrayInitializer(init *ArrayInitializer, arrayType string) {
+	element := elementType(arrayType)
+	for _, elem := range init.Elems {
+		if nested, ok := elem.(*ArrayInitializer); ok {
+			if element != typeUnknown && !isArrayType(element) {
+				c.errorf(nested, "SEM028", element)
+				element = typeUnknown
+			}
+			c.checkArrayInitializer(nested, element)
+			continue
+		}
+		typ := c.typeOfExpected(elem, element)
+		if isAssignableType(element, typ, constantValue(elem)) {
+			continue
+		}
+		if lit, ok := unparen(elem).(*Literal); ok {
+			c.errorf(elem, "SEM029", describeLiteral(lit.Kind), lit.Value, element)
+		} else {
+			c.errorf(elem, "SEM030", typ, element)
+		}
+	}
+}
+
+// checkAssignment comprueba que value, de tipo valueType, pueda asignarse a
+// la variable name de tipo target
+func (c *checker) checkAssignment(target, name string, value Expr, valueType string) {
+	if isAssignableType(target, valueType, constantValue(value)) {
+		return
+	}
+	if lit, ok := unparen(value).(*Literal); ok {
+		code := "SEM031"
+		if target == "float" && lit.Kind == KindDouble {
+			code = "SEM120"
+		}
+		c.errorf(value, code, describeLiteral(lit.Kind), lit.Value, target, name)
+		return
+	}
+	c.errorf(value, "SEM032", valueType, target, name)
+}
+
+// checkCondition comprueba que la condición de una sentencia sea boolean
+func (c *checker) checkCondition(cond Expr, statement string) {
+	if typ := c.typeOf(cond); typ != typeUnknown && !isBooleanType(typ) {
+		c.errorf(cond, "SEM033", statement, typ)
+	}
+}
+
+// typeOf analiza una expresión y devuelve su tipo
+func (c *checker) typeOf(x Expr) string {
+	switch e := x.(type) {
+	case *Literal:
+		return literalType(e.Kind)
+	case *Ident:
+		return c.resolveName(e, false)
+	case *ParenExpr:
+		return c.typeOf(e.X)
+	case *ThisExpr:
+		return c.className
+	case *SuperExpr:
+		return typeUnknown
+	case *ConstructorCall:
+		// Solo es válida como primera sentencia de un constructor, que
+		// analiza checkConstructorCall
+		c.errorf(e, "SEM126", e.keyword())
+		for _, arg := range e.Args {
+			c.typeOf(arg)
+		}
+		return "void"
+	case *UnaryExpr:
+		return c.typeOfUnary(e)
+	case *BinaryExpr:
+		return c.typeOfBinary(e)
+	case *AssignExpr:
+		return c.typeOfAssign(e)
+	case *ConditionalExpr:
+		return c.typeOfConditional(e)
+	case *CastExpr:
+		target := typeName(e.Type, 0)
+		source := c.typeOfExpected(e.X, target)
+		if !isCastable(source, target) {
+			c.errorf(e, "SEM034", source, target)
+		}
+		return target
+	case *InstanceOfExpr:
+		source := c.typeOf(e.X)
+		if isPrimitiveType(source) {
+			c.errorf(e, "SEM035", source)
+		}
+		if e.Binding != "" {
+			c.declare(e.Binding, typeName(e.Type, 0), e, nil)
+		}
+		return "boolean"
+	case *ArrayAccess:
+		arrayType := c.typeOf(e.X)
+		if arrayType != typeUnknown && !isArrayType(arrayType) {
+			c.errorf(e, "SEM036", arrayType)
+		}
+		if index := c.typeOf(e.Index); index != typeUnknown && unaryPromotion(index) != "int" {
+			c.errorf(e.Index, "SEM037", index)
+		}
+		return elementType(arrayType)
+	case *FieldAccess:
+		return c.typeOfField(e)
+	case *MethodCall:
+		return c.typeOfCall(e)
+	case *NewExpr:
+		if e.Outer != nil {
+			c.typeOf(e.Outer)
+		}
+		for _, arg := range e.Args {
+			c.typeOf(arg)
+		}
+		c.checkNew(e)
+		return typeName(e.Type, 0)
+	case *NewArrayExpr:
+		for _, length := range e.Lengths {
+			typ := c.typeOf(length)
+			if typ != typeUnknown && unaryPromotion(typ) != "int" {
+				c.errorf(length, "SEM038", typ)
+			} else if value := constantValue(length); value != nil && *value < 0 {
+				// Compila, pero lanza NegativeArraySizeException al ejecutarse
+				c.warnf(length, "SEM039", *value)
+			}
+		}
+		typ := typeName(e.Type, 0)
+		if e.Init != nil {
+			c.checkArrayInitializer(e.Init, typ)
+		}
+		return typ
+	case *ArrayInitializer:
+		// Fuera de una declaración ya se reportó en el análisis sintáctico
+		c.checkArrayInitializer(e, typeUnknown)
+		return typeUnknown
+	case *SwitchExpr:
+		return c.checkSwitch(e.Selector, e.Cases, true)
+	case *ClassLiteral:
+		return "Class"
+	case *LambdaExpr:
+		return c.checkLambda(e, typeUnknown)
+	case *MethodRef:
+		return c.checkMethodRef(e, typeUnknown)
+	}
+	return typeUnknown
+}
+
+// resolveName resuelve un nombre simple. qualifier indica que el nombre va
+// seguido de '.', donde también puede ser una clase o un paquete.
+func (c *checker) resolveName(id *Ident, qualifier bool) string {
+	if sym := c.scope.lookup(id.Name); sym != nil {
+		return sym.typ
+	}
+	if _, ok := c.types[id.Name]; ok {
+		return typeUnknown
+	}
+	if IsLibraryName(id.Name) || c.inheritsUnknown {
+		return typeUnknown
+	}
+	if typ, ok := c.staticImportType(id.Name); ok {
+		return typ
+	}
+	if _, ok := c.imported.types[id.Name]; ok || qualifier && c.imported.packages[id.Name] {
+		return typeUnknown
+	}
+	// Por convención los nombres de clase empiezan en mayúscula y los de
+	// paquete en minúscula: solo se pueden distinguir de una variable si se usan
+	// como calificador
+	if qualifier && (startsUpper(id.Name) || isPackageName(id.Name)) {
+		return typeUnknown
+	}
+	c.errorf(id, "SEM040", id.Name)
+	return typeUnknown
+}
+
+// typeOfField analiza un acceso a campo
+func (c *checker) typeOfField(e *FieldAccess) string {
+	var target string
+	if id, ok := e.X.(*Ident); ok && c.scope.lookup(id.Name) == nil && c.lookupType(id.Name) != nil {
+		// Miembro estático de un tipo declarado: Color.ROJO
+		typ, _ := c.memberType(id.Name, e.Name)
+		return typ
+	} else if ok {
+		target = c.resolveName(id, true)
+	} else if qualifiedName(e.X) != "" && isPackageName(strings.SplitN(qualifiedName(e.X), ".", 2)[0]) {
+		target = typeUnknown // nombre calificado de paquete: java.util.List
+	} else {
+		target = c.typeOf(e.X)
+	}
+	if isArrayType(target) && e.Name == "length" {
+		return "int"
+	}
+	if target == typeUnknown && qualifiedName(e.X) != "" {
+		// Campo estático de la biblioteca: System.out, Math.PI
+		if typ, ok := libraryFieldTypes[simpleTypeName(qualifiedName(e.X))+"."+e.Name]; ok {
+			return typ
+		}
+	}
+	typ, _ := c.memberType(target, e.Name)
+	return typ
+}
+
+// typeOfCall analiza una llamada a método y devuelve su tipo de retorno
+func (c *checker) typeOfCall(e *MethodCall) string {
+	// Las lambdas y referencias a método se analizan después del destino de
+	// la llamada, que decide el tipo del parámetro que las recibe
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		if !isFunctionExpr(arg) {
+			args[i] = c.typeOf(arg)
+		}
+	}
+	var target string
+	switch x := e.Target.(type) {
+	case nil:
+	case *Ident:
+		target = c.resolveName(x, true)
+	default:
+		target = c.typeOf(e.Target)
+	}
+	for i, arg := range e.Args {
+		if isFunctionExpr(arg) {
+			args[i] = c.typeOfExpected(arg, c.argumentTarget(e, target, i))
+		}
+	}
+
+	if e.Target == nil {
+		if typ, ok := c.methods[e.Name]; ok {
+			return typ
+		}
+		return c.staticImportCall(e.Name, args)
+	}
+	if x, ok := e.Target.(*Ident); ok && target == typeUnknown && c.scope.lookup(x.Name) == nil {
+		return libraryMethodType(x.Name, e.Name, args)
+	}
+
+	if target == "String" {
+		if !c.isStringMethod(e.Name) {
+			c.errorf(e, "SEM041", e.Name)
+		}
+		return stringMethodTypes[e.Name]
+	}
+	return typeUnknown
+}
+
+// isStringMethod indica si String tiene el método name: los de tipo conocido
+// y, en el análisis optimizado, los de la biblioteca de strings
+func (c *checker) isStringMethod(name string) bool {
+	if _, known := stringMethodTypes[name]; known || c.stringMethods == nil {
+		return true
+	}
+	return c.stringMethods.ValidateStringMethod(name)
+}
+
+// typeOfUnary analiza una operación unaria
+func (c *checker) typeOfUnary(e *UnaryExpr) string {
+	typ := c.typeOf(e.X)
+	if e.Op == OpIncrement || e.Op == OpDecrement {
+		c.markAssigned(e.X)
+	}
+	if typ == typeUnknown {
+		return typeUnknown
+	}
+	switch e.Op {
+	case OpNot:
+		if !isBooleanType(typ) {
+			c.errorf(e, "SEM042", typ)
+			return typeUnknown
+		}
+		return "boolean"
+	case OpBitNot:
+		if !isIntegralType(typ) {
+			c.errorf(e, "SEM043", typ)
+			return typeUnknown
+		}
+		return unaryPromotion(typ)
+	case OpIncrement, OpDecrement:
+		if !isNumericType(typ) {
+			c.errorf(e, "SEM044", kindTexts[e.Op], typ)
+			return typeUnknown
+		}
+		return typ
+	}
+	if !isNumericType(typ) {
+		c.errorf(e, "SEM044", kindTexts[e.Op], typ)
+		return typeUnknown
+	}
+	return unaryPromotion(typ)
+}
+
+// typeOfBinary analiza una operación binaria
+func (c *checker) typeOfBinary(e *BinaryExpr) string {
+	left, right := c.typeOf(e.X), c.typeOf(e.Y)
+	if e.Op == OpAdd && (left == "String" || right == "String") {
+		return "String"
+	}
+	if left == typeUnknown || right == typeUnknown {
+		switch e.Op {
+		case OpAnd, OpOr, OpEqual, OpNotEqual, OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+			return "boolean"
+		}
+		return typeUnknown
+	}
+
+	invalid := func() string {
+		c.errorf(e, "SEM045", kindTexts[e.Op], left, right)
+		return typeUnknown
+	}
+	switch e.Op {
+	case OpAdd, OpSub, OpMul, OpDiv, OpMod:
+		if !isNumericType(left) || !isNumericType(right) {
+			return invalid()
+		}
+		return binaryPromotion(left, right)
+	case OpShiftLeft, OpShiftRight, OpUnsignedShiftRight:
+		if !isIntegralType(left) || !isIntegralType(right) {
+			return invalid()
+		}
+		return unaryPromotion(left)
+	case OpLess, OpLessEqual, OpGreater, OpGreaterEqual:
+		if !isNumericType(left) || !isNumericType(right) {
+			return invalid()
+		}
+		return "boolean"
+	case OpEqual, OpNotEqual:
+		numeric := isNumericType(left) && isNumericType(right)
+		boolean := isBooleanType(left) && isBooleanType(right)
+		reference := !isPrimitiveType(left) && !isPrimitiveType(right)
+		if !numeric && !boolean && !(reference && (isAssignableType(left, right, nil) || isAssignableType(right, left, nil))) {
+			return invalid()
+		}
+		return "boolean"
+	case OpAnd, OpOr:
+		if !isBooleanType(left) || !isBooleanType(right) {
+			return invalid()
+		}
+		return "boolean"
+	case OpBitAnd, OpBitOr, OpXor:
+		if isBooleanType(left) && isBooleanType(right) {
+			return "boolean"
+		}
+		if !isIntegralType(left) || !isIntegralType(right) {
+			return invalid()
+		}
+		return binaryPromotion(left, right)
+	}
+	return typeUnknown
+}
+
+// typeOfAssign analiza una asignación simple o compuesta
+func (c *checker) typeOfAssign(e *AssignExpr) string {
+	target := c.typeOf(e.Target)
+	c.markAssigned(e.Target)
+	value := c.typeOfExpected(e.Value, target)
+	name := describeTarget(e.Target)
+
+	switch e.Op {
+	case OpAssign:
+		c.checkAssignment(target, name, e.Value, value)
+	case OpAddAssign:
+		if target == "String" || target == typeUnknown || value == typeUnknown {
+			break
+		}
+		fallthrough
+	default:
+		// Las asignaciones compuestas incluyen una conversión implícita al
+		// tipo de la variable, pero la operación debe ser válida
+		if target == typeUnknown || value == typeUnknown {
+			break
+		}
+		ok := isNumericType(target) && isNumericType(value)
+		switch e.Op {
+		case OpBitAndAssign, OpBitOrAssign, OpXorAssign:
+			ok = (isIntegralType(target) && isIntegralType(value)) || (isBooleanType(target) && isBooleanType(value))
+		case OpShiftLeftAssign, OpShiftRightAssign, OpUnsignedShiftRightAssign:
+			ok = isIntegralType(target) && isIntegralType(value)
+		}
+		if !ok {
+			c.errorf(e, "SEM045", kindTexts[e.Op], target, value)
+		}
+	}
+	return target
+}
+
+// typeOfConditional analiza "c ? a : b"
+func (c *checker) typeOfConditional(e *ConditionalExpr) string {
+	if cond := c.typeOf(e.Cond); cond != typeUnknown && !isBooleanType(cond) {
+		c.errorf(e.Cond, "SEM046", cond)
+	}
+	then, els := c.typeOf(e.Then), c.typeOf(e.Else)
+	switch {
+	case then == els:
+		return then
+	case isNumericType(then) && isNumericType(els):
+		return binaryPromotion(then, els)
+	case then == typeNull:
+		return els
+	case els == typeNull:
+		return then
+	}
+	return typeUnknown
+}
+
+// errorf reporta el error semántico code situado en at, con el mensaje del
+// catálogo formateado con args. Devuelve el diagnóstico para completarlo con
+// posiciones relacionadas o notas.
+func (c *checker) errorf(at Node, code string, args ...interface{}) *Diagnostic {
+	c.errors = append(c.errors, newDiagnostic(code, SeverityError, at, args...))
+	return &c.errors[len(c.errors)-1]
+}
+
+// warnf reporta una advertencia, que no impide compilar el programa
+func (c *checker) warnf(at Node, code string, args ...interface{}) *Diagnostic {
+	c.warnings = append(c.warnings, newDiagnostic(code, SeverityWarning, at, args...))
+	return &c.warnings[len(c.warnings)-1]
+}
+
+// isCastable indica si una conversión explícita es posible
+func isCastable(source, target string) bool {
+	if source == typeUnknown || target == typeUnknown || source == target {
+		return true
+	}
+	if isBooleanType(source) || isBooleanType(target) {
+		return isBooleanType(source) && isBooleanType(target)
+	}
+	if isPrimitiveType(target) || isPrimitiveType(source) {
+		return isNumericType(source) && isNumericType(target) || isAssignableType(target, source, nil)
+	}
+	if isArrayType(target) && isArrayType(source) {
+		targetElem, sourceElem := elementType(target), elementType(source)
+		if isPrimitiveType(targetElem) || isPrimitiveType(sourceElem) {
+			return targetElem == sourceElem
+		}
+		return isCastable(sourceElem, targetElem)
+	}
+	return !(isKnownReferenceType(target) && isKnownReferenceType(source))
+}
+
+// literalType devuelve el tipo de un literal
+func literalType(kind TokenKind) string {
+	switch kind {
+	case KindNumber:
+		return "int"
+	case KindLong:
+		return "long"
+	case KindFloat:
+		return "float"
+	case KindDouble:
+		return "double"
+	case KindString:
+		return "String"
+	case KindChar:
+		return "char"
+	case KwTrue, KwFalse:
+		return "boolean"
+	case KwNull:
+		return typeNull
+	}
+	return typeUnknown
+}
+
+// describeLiteral describe un literal para los mensajes
+func describeLiteral(kind TokenKind) phrase {
+	switch kind {
+	case KindNumber:
+		return newPhrase("phrase.integer")
+	case KwTrue, KwFalse:
+		return verbatim("boolean")
+	}
+	return verbatim(kind.String())
+}
+
+// describeTarget describe el destino de una asignación para los mensajes
+func describeTarget(x Expr) string {
+	switch e := unparen(x).(type) {
+	case *ArrayAccess:
+		return describeTarget(e.X) + "[]"
+	case *FieldAccess:
+		if name := qualifiedName(e); name != "" {
+			return name
+		}
+		return e.Name
+	}
+	return qualifiedName(unparen(x))
+}
+
+// unparen quita los paréntesis que rodean una expresión
+func unparen(x Expr) Expr {
+	for {
+		paren, ok := x.(*ParenExpr)
+		if !ok {
+			return x
+		}
+		x = paren.X
+	}
+}
+
+// constantValue evalúa una expresión constante entera (JLS §15.29)
+func constantValue(x Expr) *int64 {
+	var value int64
+	switch e := unparen(x).(type) {
+	case *Literal:
+		switch e.Kind {
+		case KindNumber:
+			v, err := parseIntegerLiteral(e.Value, false)
+			if err != nil {
+				return nil
+			}
+			value = v
+		case KindChar:
+			r, ok := charLiteralValue(Token{Type: KindChar, Value: e.Value})
+			if !ok {
+				return nil
+			}
+			value = int64(r)
+		default:
+			return nil
+		}
+	case *UnaryExpr:
+		operand := constantValue(e.X)
+		if operand == nil || e.Postfix {
+			return nil
+		}
+		switch e.Op {
+		case OpSub:
+			value = -*operand
+		case OpAdd:
+			value = *operand
+		case OpBitNot:
+			value = ^*operand
+		default:
+			return nil
+		}
+	case *BinaryExpr:
+		left, right := constantValue(e.X), constantValue(e.Y)
+		if left == nil || right == nil {
+			return nil
+		}
+		switch e.Op {
+		case OpAdd:
+			value = *left + *right
+		case OpSub:
+			value = *left - *right
+		case OpMul:
+			value = *left * *right
+		case OpDiv, OpMod:
+			if *right == 0 {
+				return nil
+			}
+			if e.Op == OpDiv {
+				value = *left / *right
+			} else {
+				value = *left % *right
+			}
+		case OpBitAnd:
+			value = *left & *right
+		case OpBitOr:
+			value = *left | *right
+		case OpXor:
+			value = *left ^ *right
+		default:
+			return nil
+		}
+		// Las operaciones entre int se desbordan como en Java
+		value = int64(int32(value))
+	default:
+		return nil
+	}
+	return &value
+}
+
+// constantInit devuelve el valor de un inicializador literal, para Variable.Value
+func constantInit(init Expr) interface{} {
+	lit, ok := init.(*Literal)
+	if !ok {
+		return nil
+	}
+	switch lit.Kind {
+	case KindNumber, KindLong:
+		if v, err := parseIntegerLiteral(lit.Value, false); err == nil {
+			return v
+		}
+	case KindFloat, KindDouble:
+		if v, err := parseFloatLiteral(lit.Value); err == nil {
+			return v
+		}
+	case KindString:
+		return lit.Value
+	case KindChar:
+		if r, ok := charLiteralValue(Token{Type: KindChar, Value: lit.Value}); ok {
+			return r
+		}
+	case KwTrue, KwFalse:
+		return lit.Kind == KwTrue
+	}
+	return nil
+}
+
+func startsUpper(name string) bool {
+	return name != "" && name[0] >= 'A' && name[0] <= 'Z'
+}
+
+// isPackageName indica si el nombre es la raíz de un paquete conocido
+func isPackageName(name string) bool {
+	switch name {
+	case "java", "javax", "jdk", "org", "com":
+		return true
+	}
+	return false
+}
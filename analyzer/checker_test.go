@@ -0,0 +1,332 @@
+// analyzer/checker_test.go
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// semanticCodes analiza el código y devuelve los códigos de sus diagnósticos
+// semánticos
+func semanticCodes(t *testing.T, code string) []string {
+	t.Helper()
+	_, diagnostics := AnalyzeSemantics(Lex(code))
+	codes := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		codes[i] = d.Code
+	}
+	return codes
+}
+
+func TestPatternBindingsAfterIf(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{
+			name: "then abrupto con condición negada",
+			body: "if (!(o instanceof String s)) { return; }\nint n = s.length();",
+		},
+		{
+			name: "then abrupto con ||",
+			body: "if (!(o instanceof Integer i) || i > 3) throw new RuntimeException();\nint k = i + 1;",
+		},
+		{
+			name: "else abrupto",
+			body: "if (o instanceof Long l) { } else { return; }\nlong z = l;",
+		},
+		{
+			name: "then que termina normalmente",
+			body: "if (!(o instanceof String s)) { }\nint n = s.length();",
+			want: []string{"SEM040"},
+		},
+		{
+			name: "patrón verdadero con then abrupto",
+			body: "if (o instanceof Double d) { return; }\ndouble w = d;",
+			want: []string{"SEM040"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := "public class A {\n  void m(Object o) {\n" + tt.body + "\n  }\n}\n"
+			got := semanticCodes(t, code)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorNodesAreSilent(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"return sin expresión", "class A { void other() { return\n} }"},
+		{"operando que falta", "class A { void m() { int x = 1 + ; x++; } }"},
+		{"cabecera del for sin ';'", "class A { void f() { for (int i = 0; i < 10 i++) { int y = i; } } }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := semanticCodes(t, tt.code); len(got) > 0 {
+				t.Errorf("errores en cascada: %v", got)
+			}
+		})
+	}
+}
+
+func TestNegativeArraySizeIsWarning(t *testing.T) {
+	ok, diagnostics := AnalyzeSemantics(Lex("class A { void m() { int[] a = new int[-1]; } }"))
+	if !ok || len(diagnostics) != 1 || diagnostics[0].Code != "SEM039" || diagnostics[0].Severity != SeverityWarning {
+		t.Errorf("se esperaba solo la advertencia SEM039: ok = %v, %+v", ok, diagnostics)
+	}
+}
+
+func TestSwitchExhaustivenessNamesMissingCases(t *testing.T) {
+	declarations := `enum Color { RED, GREEN, BLUE }
+sealed interface Shape permits Circle, Square {}
+final class Circle implements Shape {}
+final class Square implements Shape {}
+`
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"enum", "int f(Color c) { return switch (c) { case RED -> 1; }; }", "los casos 'GREEN', 'BLUE'"},
+		{"sealed", "int g(Shape s) { return switch (s) { case Circle c -> 1; }; }", "los casos 'Square'"},
+		{"int", "int k(int x) { return switch (x) { case 1 -> 1; }; }", "todos los valores posibles"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, diagnostics := AnalyzeSemantics(Lex(declarations + "class A { " + tt.body + " }"))
+			if len(diagnostics) != 1 || diagnostics[0].Code != "SEM076" ||
+				!reflect.DeepEqual(diagnostics[0].Args, []string{tt.want}) {
+				t.Errorf("se esperaba SEM076 con %q: %+v", tt.want, diagnostics)
+			}
+		})
+	}
+}
+
+func TestInheritedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{
+			name: "campos de la superclase",
+			code: "class Animal { protected String name; int age; } class Dog extends Animal { String bark() { age++; return name; } }",
+		},
+		{
+			name: "constantes de una interfaz",
+			code: "interface K { int MAX = 1; } class U implements K { int f() { return MAX; } }",
+		},
+		{
+			name: "jerarquía de varios niveles",
+			code: "class A { int a; } class B extends A { int b; } class C extends B { int f() { return a + b; } }",
+		},
+		{
+			name: "clase anónima",
+			code: "class A { int a; } class M { void m() { A x = new A() { int f() { return a; } }; } }",
+		},
+		{
+			name: "jerarquía cíclica",
+			code: "class X extends Y { } class Y extends X { int f() { return z; } }",
+			want: []string{"SEM040"},
+		},
+		{
+			name: "campo privado",
+			code: "class A { private int p; } class B extends A { int f() { return p; } }",
+			want: []string{"SEM040"},
+		},
+		{
+			name: "constante de interfaz final",
+			code: "interface K { int MAX = 1; } class U implements K { void f() { MAX = 2; } }",
+			want: []string{"SEM004"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := semanticCodes(t, tt.code)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenericMethodResults(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"lista del parámetro de tipo", "List<String> ls = wrap(\"x\");", nil},
+		{"mapa de dos parámetros de tipo", "Map<String, Integer> mp = pair(\"a\", 1);", nil},
+		{"parámetro de tipo anidado", "Map<String, List<Integer>> mm = multi();", nil},
+		{"parámetro de tipo de la clase", "List<E> copy = items;", nil},
+		{"genéricos invariantes", "List<Object> bad = new ArrayList<String>();", []string{"SEM032"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := `import java.util.*;
+class G<E> {
+  static <T> List<T> wrap(T t) { return null; }
+  static <K, V> Map<K, V> pair(K k, V v) { return null; }
+  static <V> Map<String, List<V>> multi() { return null; }
+  List<E> items;
+  void m() { ` + tt.body + ` }
+}`
+			got := semanticCodes(t, code)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStringMethodsOnBothAnalyzers(t *testing.T) {
+	tests := []struct {
+		call string
+		want []string
+	}{
+		{"s.isEmpty()", nil},
+		{"s.isBlank()", nil},
+		{"\"abc\".toCharArray()", nil},
+		{"s.length()", nil},
+		{"s.noExiste()", []string{"SEM041"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.call, func(t *testing.T) {
+			code := "class A { void m(String s) { Object x = " + tt.call + "; } }"
+			_, diagnostics := NewEnhancedSemanticAnalyzer().AnalyzeOptimized(Lex(code))
+			var got []string
+			for _, d := range diagnostics {
+				got = append(got, d.Code)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControlFlowAndLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"break con etiqueta de un bucle exterior", "outer: for (;;) { inner: while (true) { break outer; } }", nil},
+		{"break con etiqueta de un bloque", "block: { if (x > 0) break block; }", nil},
+		{"break fuera de un bucle", "break;", []string{"SEM007"}},
+		{"continue fuera de un bucle", "continue;", []string{"SEM010"}},
+		{"etiqueta no definida", "outer: for (;;) { continue missing; }", []string{"SEM005"}},
+		{"continue a un bloque", "block: { continue block; }", []string{"SEM008"}},
+		{"etiqueta repetida", "a: for (;;) { a: while (true) { } }", []string{"SEM016"}},
+		{"break en una expresión switch", "int y = switch (x) { case 1 -> { break; } default -> 0; };", []string{"SEM006"}},
+		{"continue en una expresión switch", "int y = switch (x) { case 1: yield 1; default: continue; };", []string{"SEM009"}},
+		{"yield fuera de una expresión switch", "yield 3;", []string{"SEM011"}},
+		{"throw de un valor que no es excepción", "throw 5;", []string{"SEM012"}},
+		{"synchronized sobre un primitivo", "synchronized (x) { }", []string{"SEM013"}},
+		{"return con valor en un método void", "return 1;", []string{"SEM021"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := semanticCodes(t, "class A { void m(int x) { "+tt.body+" } }")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTryChecks(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"catch del más concreto al más general", "try { } catch (RuntimeException r) { } catch (Exception e) { }", nil},
+		{"multi-catch sin relación", "try { } catch (IllegalArgumentException | IllegalStateException e) { }", nil},
+		{"multi-catch con subclase", "try { } catch (RuntimeException | Exception e) { }", []string{"SEM071"}},
+		{"catch inalcanzable", "try { } catch (Exception e) { } catch (RuntimeException r) { }", []string{"SEM072"}},
+		{"catch de un tipo que no es excepción", "try { } catch (String e) { }", []string{"SEM070"}},
+		{"recurso declarado", "try (java.io.Reader r = null) { }", nil},
+		{"asignación a un recurso declarado", "try (java.io.Reader r = null) { r = null; }", []string{"SEM004"}},
+		{"variable efectivamente final como recurso", "java.io.Reader in = null; try (in) { }", nil},
+		{"variable modificada como recurso", "java.io.Reader in = null; in = null; try (in) { }", []string{"SEM001"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := semanticCodes(t, "class A { void m() { "+tt.body+" } }")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want []string
+	}{
+		{"import usado", "package com.example; import java.util.List; class A { List<String> l; }", nil},
+		{"import bajo demanda", "import java.util.*; class A { List<String> l; }", nil},
+		{"import estático usado", "import static java.lang.Math.max; class A { int f() { return max(1, 2); } }", nil},
+		{"paquete con mayúsculas", "package com.Example; class A { }", []string{"SEM084"}},
+		{"import duplicado", "import java.util.List; import java.util.List; class A { List<String> l; }", []string{"SEM085"}},
+		{"import de java.lang", "import java.lang.String; class A { String s; }", []string{"SEM086"}},
+		{"import no usado", "import java.util.List; class A { }", []string{"SEM089"}},
+		{"import usado en {@link}", "import java.util.List; /** Usa {@link List#size()}. */ class A { }", nil},
+		{"import usado en @see", "import java.util.List;\n/**\n * Lista.\n * @see List\n */\nclass A { }", nil},
+		{"import usado en @throws", "import java.io.IOException;\nclass A {\n  /** @throws IOException si falla */\n  void m() { }\n}", nil},
+		{"nombre citado sin etiqueta", "import java.util.List; /** Devuelve una List. */ class A { }", []string{"SEM089"}},
+		{"mismo nombre simple de dos paquetes", "import java.util.List; import java.awt.List; class A { }", []string{"SEM089", "SEM087"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := semanticCodes(t, tt.code)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLambdaAndMethodRefTyping(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want []string
+	}{
+		{"lambdas válidas", "Function<String, Integer> f = s -> s.length(); Runnable r = () -> System.out.println(); Supplier<A> n = A::new; Runnable h = this::run; Function<String, Integer> l = String::length;", nil},
+		{"resultado incompatible", "Function<String, Integer> f = s -> s;", []string{"SEM022"}},
+		{"expresión en una lambda void", "Runnable r = () -> 1;", []string{"SEM116"}},
+		{"return sin valor", "Supplier<String> s = () -> { return; };", []string{"SEM018"}},
+		{"return con valor en una lambda void", "Runnable r = () -> { return 1; };", []string{"SEM020"}},
+		{"número de parámetros", "BiFunction<String, String, Integer> f = s -> 1;", []string{"SEM114"}},
+		{"tipo del parámetro", "Function<String, Integer> f = (Integer s) -> 1;", []string{"SEM115"}},
+		{"lambda sin interfaz funcional", "String s = () -> 1;", []string{"SEM113"}},
+		{"parámetro redeclarado", "Consumer<String> c = s -> { int s = 1; };", []string{"SEM002"}},
+		{"parámetro fuera de la lambda", "Consumer<String> c = s -> { }; s.length();", []string{"SEM040"}},
+		{"var con lambda", "var f = (String s) -> s;", []string{"SEM025"}},
+		{"referencia a un método inexistente", "Runnable r = A::nada;", []string{"SEM118"}},
+		{"referencia con resultado incompatible", "Function<String, Integer> f = String::toUpperCase;", []string{"SEM119"}},
+		{"referencia sin interfaz funcional", "int n = String::length;", []string{"SEM117"}},
+		{"var con referencia", "var f = String::length;", []string{"SEM026"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := semanticCodes(t, "import java.util.function.*; class A { void run() { } void m() { "+tt.body+" } }")
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
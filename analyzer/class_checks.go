@@ -0,0 +1,547 @@
+// analyzer/class_checks.go
+package analyzer
+
+import "strings"
+
+// collectTypes registra las clases declaradas, sus métodos y los accesores
+// de los records
+func (c *checker) collectTypes(decls []*ClassDecl) {
+	for _, decl := range decls {
+		if previous, exists := c.types[decl.Name]; exists {
+			c.errorf(decl, "SEM047", decl.Name).addLabel(previous, "SEM047.previous")
+		}
+		c.types[decl.Name] = decl
+		for _, component := range decl.Components {
+			c.methods[component.Name] = typeName(component.Type, 0)
+		}
+		var nested []*ClassDecl
+		for _, member := range decl.Members {
+			switch m := member.(type) {
+			case *MethodDecl:
+				if !m.IsConstructor() {
+					c.methods[m.Name] = typeName(m.ResultType, 0)
+					c.methodDecls[m.Name] = append(c.methodDecls[m.Name], m)
+				}
+			case *ClassDecl:
+				nested = append(nested, m)
+			}
+		}
+		c.collectTypes(nested)
+	}
+}
+
+// lookupType busca un tipo declarado en el código por su nombre simple o calificado
+func (c *checker) lookupType(name string) *ClassDecl {
+	return c.types[simpleTypeName(name)]
+}
+
+// checkClass analiza la cabecera y los miembros de un tipo
+func (c *checker) checkClass(decl *ClassDecl) {
+	outerClass, outerInherits := c.className, c.inheritsUnknown
+	c.className = decl.Name
+	inherited, unknown := c.inheritedFields(decl.Name, c.supertypes(decl))
+	if unknown {
+		c.inheritsUnknown = true
+	}
+	// Los campos heredados quedan debajo de los propios, que los ocultan
+	c.scope = newScope(c.scope, true)
+	for _, sym := range inherited {
+		c.scope.vars[sym.name] = sym
+	}
+	c.checkTypeParams(decl.TypeParams)
+	c.checkTypeHeader(decl)
+	c.checkMemberRules(decl)
+
+	// Las constantes de un enum y los componentes de un record son campos final
+	var fields []*symbol
+	for _, constant := range decl.Constants {
+		fields = append(fields, &symbol{name: constant.Name, typ: decl.Name, pos: constant.Span, final: true})
+	}
+	for _, component := range decl.Components {
+		fields = append(fields, &symbol{name: component.Name, typ: typeName(component.Type, 0), pos: component.Span, final: true})
+	}
+	if len(decl.Constants) > 0 {
+		c.scope = newScope(c.scope, true)
+		for _, sym := range fields {
+			c.scope.vars[sym.name] = sym
+		}
+		for _, constant := range decl.Constants {
+			for _, arg := range constant.Args {
+				c.typeOf(arg)
+			}
+			if constant.Body != nil {
+				c.checkMembers(constant.Body, nil)
+			}
+		}
+		c.scope = c.scope.parent
+	}
+
+	c.checkMembers(decl.Members, fields)
+	c.scope = c.scope.parent
+	c.className, c.inheritsUnknown = outerClass, outerInherits
+}
+
+// checkTypeHeader comprueba extends, implements y permits de un tipo
+func (c *checker) checkTypeHeader(decl *ClassDecl) {
+	for _, super := range decl.Extends {
+		parent := c.lookupType(super.Name)
+		switch {
+		case parent == nil:
+		case decl.Kind == "class" && parent.IsInterface():
+			c.errorf(super, "SEM048", parent.Name)
+		case decl.IsInterface() && !parent.IsInterface():
+			c.errorf(super, "SEM049", parent.Name, describeTypeKind(parent))
+		case parent.Kind == "enum" || parent.Kind == "record":
+			c.errorf(super, "SEM050", parent.Kind, parent.Name)
+		case parent.Modifiers.Has("final"):
+			c.errorf(super, "SEM051", parent.Name)
+		}
+	}
+	for _, super := range decl.Implements {
+		if parent := c.lookupType(super.Name); parent != nil && !parent.IsInterface() {
+			c.errorf(super, "SEM052", parent.Name)
+		}
+	}
+
+	// Un subtipo directo de un tipo sealed debe estar permitido y declarar
+	// cómo continúa la jerarquía
+	for _, super := range c.supertypes(decl) {
+		parent := c.lookupType(super.Name)
+		if parent == nil || !parent.Modifiers.Has("sealed") {
+			continue
+		}
+		if len(parent.Permits) > 0 && !containsType(parent.Permits, decl.Name) {
+			c.errorf(super, "SEM053", decl.Name, parent.Name)
+		}
+		implicitlyFinal := decl.Kind == "enum" || decl.Kind == "record"
+		if !implicitlyFinal && !decl.Modifiers.Has("final") && !decl.Modifiers.Has("sealed") && !decl.Modifiers.Has("non-sealed") {
+			c.errorf(decl, "SEM054", decl.Name, parent.Name)
+		}
+	}
+	if decl.Modifiers.Has("sealed") {
+		for _, permitted := range decl.Permits {
+			if sub := c.lookupType(permitted.Name); sub != nil && !containsType(c.supertypes(sub), decl.Name) {
+				c.errorf(permitted, "SEM055", sub.Name, decl.Name)
+			}
+		}
+		if len(decl.Permits) == 0 && len(c.directSubtypes(decl.Name)) == 0 {
+			c.errorf(decl, "SEM056", decl.Name)
+		}
+	}
+}
+
+// checkTypeParams comprueba los límites de los parámetros de tipo: solo el
+// primero puede ser una clase y ninguno un tipo primitivo
+func (c *checker) checkTypeParams(params []*TypeParam) {
+	for _, param := range params {
+		for i, bound := range param.Bounds {
+			if isPrimitiveType(bound.Name) && bound.Dims == 0 {
+				c.errorf(bound, "SEM057", param.Name, bound.Name)
+				continue
+			}
+			if decl := c.lookupType(bound.Name); i > 0 && decl != nil && !decl.IsInterface() {
+				c.errorf(bound, "SEM058", decl.Name, param.Name)
+			}
+		}
+	}
+}
+
+// checkMemberRules comprueba los métodos, constructores y campos según el
+// tipo que los declara
+func (c *checker) checkMemberRules(decl *ClassDecl) {
+	abstractClass := decl.Modifiers.Has("abstract") || decl.IsInterface()
+	for _, member := range decl.Members {
+		switch m := member.(type) {
+		case *MethodDecl:
+			if m.IsConstructor() {
+				if decl.Kind == "enum" && (m.Modifiers.Has("public") || m.Modifiers.Has("protected")) {
+					c.errorf(m, "SEM059")
+				}
+				continue
+			}
+			abstract := m.Modifiers.Has("abstract")
+			switch {
+			case decl.IsInterface() && m.Body != nil && !m.Modifiers.Has("default") &&
+				!m.Modifiers.Has("static") && !m.Modifiers.Has("private"):
+				c.errorf(m, "SEM060", m.Name)
+			case abstract && m.Body != nil:
+				c.errorf(m, "SEM061", m.Name)
+			case abstract && !abstractClass && decl.Kind != "enum":
+				c.errorf(m, "SEM062", decl.Name, m.Name)
+			case m.Body == nil && !abstract && !m.Modifiers.Has("native") && !decl.IsInterface():
+				c.errorf(m, "SEM063", m.Name)
+			}
+		case *FieldDecl:
+			if decl.Kind == "record" && !m.Modifiers.Has("static") {
+				c.errorf(m, "SEM064")
+			}
+		case *InitializerBlock:
+			if decl.IsInterface() {
+				c.errorf(m, "SEM065")
+			} else if decl.Kind == "record" && !m.Static {
+				c.errorf(m, "SEM066")
+			}
+		}
+	}
+}
+
+// checkNew comprueba la creación de una instancia y analiza el cuerpo de la
+// clase anónima, si lo hay
+func (c *checker) checkNew(e *NewExpr) {
+	decl := c.lookupType(e.Type.Name)
+	if decl != nil && e.Body == nil {
+		c.checkInstantiable(decl, e)
+	}
+	if e.Body != nil {
+		outerInherits := c.inheritsUnknown
+		var inherited []*symbol
+		if decl == nil {
+			c.inheritsUnknown = true
+		} else {
+			// La clase anónima hereda los campos del tipo y los de sus supertipos
+			var unknown bool
+			inherited, unknown = c.inheritedFields("", []*TypeNode{e.Type})
+			c.inheritsUnknown = c.inheritsUnknown || unknown
+		}
+		c.scope = newScope(c.scope, true)
+		for _, sym := range inherited {
+			c.scope.vars[sym.name] = sym
+		}
+		c.checkMembers(e.Body, nil)
+		c.scope = c.scope.parent
+		c.inheritsUnknown = outerInherits
+	}
+}
+
+// leadingConstructorCall devuelve la llamada "this(...)" o "super(...)" con
+// la que empieza el cuerpo de un constructor, o nil
+func leadingConstructorCall(stmts []Stmt) *ConstructorCall {
+	if len(stmts) == 0 {
+		return nil
+	}
+	if stmt, ok := stmts[0].(*ExprStmt); ok {
+		call, _ := stmt.X.(*ConstructorCall)
+		return call
+	}
+	return nil
+}
+
+// checkConstructorCall comprueba la llamada explícita a un constructor de la
+// clase actual o de su superclase: algún constructor debe aceptar los
+// argumentos. Los tipos declarados en otro archivo no se comprueban.
+func (c *checker) checkConstructorCall(call *ConstructorCall) {
+	decl := c.lookupType(c.className)
+	var target *ClassDecl // nil con known es Object
+	known := false
+	switch {
+	case decl == nil:
+	case !call.Super:
+		target, known = decl, true
+	case decl.Kind == "enum" || decl.Kind == "record":
+		c.errorf(call, "SEM128", decl.Kind)
+	case len(decl.Extends) == 0:
+		known = true
+	default:
+		target = c.lookupType(decl.Extends[0].Name)
+		known = target != nil
+	}
+	candidates := constructorSignatures(target)
+
+	// Las lambdas solo tienen tipo si un único constructor tiene esa aridad
+	var only [][]string
+	for _, params := range candidates {
+		if acceptsArity(params, len(call.Args)) {
+			only = append(only, params)
+		}
+	}
+	args := make([]string, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = typeUnknown
+		switch {
+		case !isFunctionExpr(arg):
+			args[i] = c.typeOf(arg)
+		case len(only) == 1:
+			c.typeOfExpected(arg, parameterType(only[0], i))
+		}
+	}
+
+	if !known {
+		return
+	}
+	for _, params := range candidates {
+		if acceptsArguments(params, args) {
+			return
+		}
+	}
+	name := "Object"
+	if target != nil {
+		name = target.Name
+	}
+	c.errorf(call, "SEM127", name, strings.Join(args, ", "))
+}
+
+// constructorSignatures devuelve los tipos de los parámetros de cada
+// constructor del tipo; "T..." marca un parámetro de aridad variable. Sin
+// constructores declarados el tipo tiene el constructor por defecto, y un
+// record tiene además el canónico. Con decl nil se usa el de Object.
+func constructorSignatures(decl *ClassDecl) [][]string {
+	var signatures [][]string
+	if decl == nil {
+		return [][]string{{}}
+	}
+	for _, member := range decl.Members {
+		method, ok := member.(*MethodDecl)
+		if !ok || !method.IsConstructor() || method.Compact {
+			continue
+		}
+		params := make([]string, len(method.Params))
+		for i, param := range method.Params {
+			params[i] = typeName(param.Type, 0)
+			if param.Varargs {
+				params[i] += "..."
+			}
+		}
+		signatures = append(signatures, params)
+	}
+	if decl.Kind == "record" {
+		canonical := make([]string, len(decl.Components))
+		for i, component := range decl.Components {
+			canonical[i] = typeName(component.Type, 0)
+		}
+		signatures = append(signatures, canonical)
+	} else if len(signatures) == 0 {
+		signatures = append(signatures, []string{})
+	}
+	return signatures
+}
+
+// acceptsArity indica si un constructor admite n argumentos
+func acceptsArity(params []string, n int) bool {
+	if len(params) > 0 && strings.HasSuffix(params[len(params)-1], "...") {
+		return n >= len(params)-1
+	}
+	return n == len(params)
+}
+
+// parameterType devuelve el tipo del parámetro que recibe el argumento i; los
+// argumentos de aridad variable reciben el tipo del elemento
+func parameterType(params []string, i int) string {
+	if i >= len(params)-1 && len(params) > 0 && strings.HasSuffix(params[len(params)-1], "...") {
+		return strings.TrimSuffix(params[len(params)-1], "...")
+	}
+	return params[i]
+}
+
+// acceptsArguments indica si un constructor acepta argumentos de los tipos dados
+func acceptsArguments(params, args []string) bool {
+	if !acceptsArity(params, len(args)) {
+		return false
+	}
+	for i, arg := range args {
+		param := parameterType(params, i)
+		if i == len(params)-1 && len(args) == len(params) && strings.HasSuffix(params[i], "...") &&
+			isAssignableType(param+"[]", arg, nil) {
+			continue // array pasado directamente como argumentos variables
+		}
+		if !isAssignableType(param, arg, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkInstantiable comprueba que se pueda crear una instancia del tipo
+// declarado, con new o con una referencia a constructor
+func (c *checker) checkInstantiable(decl *ClassDecl, at Node) {
+	switch {
+	case decl.IsInterface():
+		c.errorf(at, "SEM067", decl.Name)
+	case decl.Kind == "enum":
+		c.errorf(at, "SEM068", decl.Name)
+	case decl.Modifiers.Has("abstract"):
+		c.errorf(at, "SEM069", decl.Name)
+	}
+}
+
+// memberType devuelve el tipo de un campo, constante de enum o componente
+// de record de un tipo declarado, buscando también en sus superclases
+func (c *checker) memberType(owner, name string) (string, bool) {
+	decl := c.lookupType(owner)
+	for depth := 0; decl != nil && depth < 32; depth++ {
+		for _, constant := range decl.Constants {
+			if constant.Name == name {
+				return decl.Name, true
+			}
+		}
+		for _, component := range decl.Components {
+			if component.Name == name {
+				return typeName(component.Type, 0), true
+			}
+		}
+		for _, member := range decl.Members {
+			if field, ok := member.(*FieldDecl); ok {
+				for _, v := range field.Vars {
+					if v.Name == name {
+						return typeName(field.Type, v.Dims), true
+					}
+				}
+			}
+		}
+		if len(decl.Extends) == 0 {
+			break
+		}
+		decl = c.lookupType(decl.Extends[0].Name)
+	}
+	return typeUnknown, false
+}
+
+// supertypes devuelve los supertipos directos de una declaración
+func (c *checker) supertypes(decl *ClassDecl) []*TypeNode {
+	return append(decl.Extends[:len(decl.Extends):len(decl.Extends)], decl.Implements...)
+}
+
+// inheritedFields devuelve los campos que el tipo name hereda de sus
+// supertipos declarados en el código, recorriendo la jerarquía sin repetir
+// tipos: los de los supertipos más cercanos primero, que ocultan a los
+// lejanos. Indica también si algún supertipo es desconocido.
+func (c *checker) inheritedFields(name string, supertypes []*TypeNode) (fields []*symbol, unknown bool) {
+	visited := map[string]bool{name: true}
+	names := make(map[string]bool)
+	pending := append([]*TypeNode(nil), supertypes...)
+	for len(pending) > 0 {
+		super := pending[0]
+		pending = pending[1:]
+		parent := c.lookupType(super.Name)
+		if parent == nil {
+			unknown = true
+			continue
+		}
+		if visited[parent.Name] {
+			continue
+		}
+		visited[parent.Name] = true
+		for _, member := range parent.Members {
+			field, ok := member.(*FieldDecl)
+			if !ok || field.Modifiers.Has("private") {
+				continue
+			}
+			for _, v := range field.Vars {
+				if names[v.Name] {
+					continue
+				}
+				names[v.Name] = true
+				// Los campos de una interfaz son constantes
+				final := parent.IsInterface() || field.Modifiers.Has("final") && v.Init != nil
+				fields = append(fields, &symbol{name: v.Name, typ: typeName(field.Type, v.Dims), pos: v.Span, final: final})
+			}
+		}
+		pending = append(pending, c.supertypes(parent)...)
+	}
+	return fields, unknown
+}
+
+// directSubtypes devuelve los tipos declarados que extienden o implementan name
+func (c *checker) directSubtypes(name string) []*ClassDecl {
+	var subtypes []*ClassDecl
+	for _, decl := range c.types {
+		if containsType(c.supertypes(decl), name) {
+			subtypes = append(subtypes, decl)
+		}
+	}
+	return subtypes
+}
+
+// permittedSubtypes devuelve los subtipos directos de un tipo sealed: los de
+// su cláusula permits o, si no la tiene, los declarados en el mismo archivo
+func (c *checker) permittedSubtypes(decl *ClassDecl) ([]*ClassDecl, bool) {
+	if len(decl.Permits) == 0 {
+		return c.directSubtypes(decl.Name), true
+	}
+	var subtypes []*ClassDecl
+	for _, permitted := range decl.Permits {
+		sub := c.lookupType(permitted.Name)
+		if sub == nil {
+			return nil, false // subtipo declarado en otro archivo
+		}
+		subtypes = append(subtypes, sub)
+	}
+	return subtypes, true
+}
+
+// uncoveredCases indica si las ramas cubren todos los valores del selector
+// sin default: todas las constantes de un enum o todos los subtipos de un
+// tipo sealed. Si no, devuelve las constantes o los subtipos que faltan, o
+// ninguno si el tipo no se puede cubrir. Los tipos primitivos, String y
+// Object nunca se cubren; de los tipos desconocidos no se reporta nada.
+func (c *checker) uncoveredCases(selectorType string, cases []*SwitchCase) (missing []string, exhaustive bool) {
+	if isPrimitiveType(unbox(selectorType)) || selectorType == "String" || selectorType == "Object" {
+		return nil, false
+	}
+	decl := c.lookupType(selectorType)
+	if decl == nil {
+		return nil, true
+	}
+	if decl.Kind == "enum" {
+		covered := make(map[string]bool)
+		for _, sc := range cases {
+			for _, label := range sc.Labels {
+				if name := qualifiedName(label); name != "" {
+					covered[simpleTypeName(name)] = true
+				}
+			}
+		}
+		for _, constant := range decl.Constants {
+			if !covered[constant.Name] {
+				missing = append(missing, constant.Name)
+			}
+		}
+		return missing, len(missing) == 0
+	}
+	missing = c.uncoveredSubtypes(decl, cases, 0)
+	return missing, len(missing) == 0
+}
+
+// uncoveredSubtypes devuelve los tipos que los patrones sin condición no
+// cubren: ninguno si nombran el tipo directamente o, si es sealed, los que
+// faltan de cada uno de sus subtipos
+func (c *checker) uncoveredSubtypes(decl *ClassDecl, cases []*SwitchCase, depth int) []string {
+	for _, sc := range cases {
+		if sc.Pattern != nil && sc.Guard == nil && !sc.Pattern.Record &&
+			simpleTypeName(sc.Pattern.Type.Name) == decl.Name {
+			return nil
+		}
+	}
+	if !decl.Modifiers.Has("sealed") || depth > 16 {
+		return []string{decl.Name}
+	}
+	subtypes, known := c.permittedSubtypes(decl)
+	if !known {
+		return nil
+	}
+	if len(subtypes) == 0 {
+		return []string{decl.Name}
+	}
+	var missing []string
+	for _, sub := range subtypes {
+		missing = append(missing, c.uncoveredSubtypes(sub, cases, depth+1)...)
+	}
+	return missing
+}
+
+// containsType indica si la lista contiene el tipo name
+func containsType(types []*TypeNode, name string) bool {
+	for _, t := range types {
+		if simpleTypeName(t.Name) == simpleTypeName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeTypeKind describe la clase de tipo para los mensajes: "una clase"
+func describeTypeKind(decl *ClassDecl) phrase {
+	if decl.Kind == "@interface" {
+		return newPhrase("phrase.annotation_type")
+	}
+	return newPhrase("phrase." + decl.Kind)
+}
@@ -0,0 +1,75 @@
+// analyzer/comments.go
+package analyzer
+
+// lexComment reconoce un comentario ("//", "/* */" o "/** */") que empieza en
+// runes[*i] y avanza la posición. Devuelve nil si no hay un comentario ahí.
+func lexComment(runes []rune, i *int, line *int, col *int) *Token {
+	if *i+1 >= len(runes) || runes[*i] != '/' || (runes[*i+1] != '/' && runes[*i+1] != '*') {
+		return nil
+	}
+
+	start := *i
+	startLine := *line
+	startCol := *col
+
+	// Comentario de línea: termina antes del salto de línea
+	if runes[*i+1] == '/' {
+		for *i < len(runes) && runes[*i] != '\n' && runes[*i] != '\r' {
+			*i++
+			*col++
+		}
+		return &Token{Type: KindComment, Value: string(runes[start:*i]), Line: startLine, Col: startCol}
+	}
+
+	// Comentario de bloque: buscar el cierre "*/"
+	*i += 2
+	*col += 2
+	for *i < len(runes) {
+		if runes[*i] == '*' && *i+1 < len(runes) && runes[*i+1] == '/' {
+			*i += 2
+			*col += 2
+			text := string(runes[start:*i])
+			token := &Token{Type: KindComment, Value: text, Line: startLine, Col: startCol}
+			if isJavadoc(text) {
+				token.Doc = ParseJavadoc(text)
+			}
+			return token
+		}
+		if runes[*i] == '\n' {
+			*line++
+			*col = 1
+		} else {
+			*col++
+		}
+		*i++
+	}
+
+	return lexicalError("LEX009", startLine, startCol, "")
+}
+
+// isJavadoc indica si un comentario de bloque es de documentación ("/** ... */")
+func isJavadoc(text string) bool {
+	return len(text) > 4 && text[:3] == "/**" && text != "/**/"
+}
+
+// significantTokens descarta los comentarios, que no participan en el análisis
+// sintáctico ni semántico. Si no hay comentarios devuelve el mismo slice.
+func significantTokens(tokens []Token) []Token {
+	count := 0
+	for _, token := range tokens {
+		if token.Type == KindComment {
+			count++
+		}
+	}
+	if count == 0 {
+		return tokens
+	}
+
+	result := make([]Token, 0, len(tokens)-count)
+	for _, token := range tokens {
+		if token.Type != KindComment {
+			result = append(result, token)
+		}
+	}
+	return result
+}
@@ -0,0 +1,533 @@
+// analyzer/declarations.go
+package analyzer
+
+// modifierKeywords palabras reservadas que actúan como modificadores
+var modifierKeywords = map[TokenKind]bool{
+	KwPublic: true, KwProtected: true, KwPrivate: true, KwStatic: true,
+	KwAbstract: true, KwFinal: true, KwNative: true, KwSynchronized: true,
+	KwTransient: true, KwVolatile: true, KwStrictfp: true,
+}
+
+// parseModifiers analiza los modificadores y anotaciones que preceden a
+// una declaración
+func (p *Parser) parseModifiers() Modifiers {
+	var modifiers Modifiers
+	for {
+		token := p.peek()
+		var modifier string
+		switch n := p.contextualModifierLength(p.pos); {
+		case p.atAnnotation():
+			modifiers.Annotations = append(modifiers.Annotations, p.parseAnnotation())
+			continue
+		case n > 0:
+			modifier = contextualModifierText(p.tokens[p.pos : p.pos+n])
+			p.pos += n - 1
+		case token.Type == KwDefault && p.peekAt(1).Type != OpColon && p.peekAt(1).Type != OpArrow:
+			// Método default de una interfaz; "default:" es una etiqueta de switch
+			modifier = token.Value
+		case !modifierKeywords[token.Type]:
+			return modifiers
+		case token.Type == KwSynchronized && p.peekAt(1).Type == KindLParen:
+			// "synchronized (x) { ... }" es una sentencia, no un modificador
+			return modifiers
+		default:
+			modifier = token.Value
+		}
+		p.next()
+		if modifiers.Has(modifier) {
+			p.errorf(token, "SYN019", modifier)
+		}
+		modifiers.Keywords = append(modifiers.Keywords, modifier)
+	}
+}
+
+// atAnnotation indica si empieza una anotación; "@interface" declara un tipo
+// de anotación
+func (p *Parser) atAnnotation() bool {
+	return p.at(KindAt) && p.peekAt(1).Type != KwInterface
+}
+
+// parseAnnotations analiza las anotaciones consecutivas, como las de uso de tipo
+func (p *Parser) parseAnnotations() []*Annotation {
+	var annotations []*Annotation
+	for p.atAnnotation() {
+		annotations = append(annotations, p.parseAnnotation())
+	}
+	return annotations
+}
+
+// parseAnnotation analiza "@Nombre", "@Nombre(valor)" o "@Nombre(a = 1, b = {2, 3})"
+func (p *Parser) parseAnnotation() *Annotation {
+	start := p.next()
+	annotation := &Annotation{Name: p.parseQualifiedName("SYN105")}
+	if p.accept(KindLParen) {
+		annotation.Args = []*AnnotationArg{}
+		for !p.at(KindRParen) {
+			argStart := p.peek()
+			arg := &AnnotationArg{}
+			if p.at(KindIdentifier) && p.peekAt(1).Type == OpAssign {
+				arg.Name = p.next().Value
+				p.next()
+			}
+			arg.Value = p.parseElementValue()
+			arg.Span = p.spanFrom(argStart)
+			annotation.Args = append(annotation.Args, arg)
+			if !p.accept(KindComma) {
+				break
+			}
+		}
+		p.expect(KindRParen, "SYN020", annotation.Name)
+		if len(annotation.Args) > 1 {
+			for _, arg := range annotation.Args {
+				if arg.Name == "" {
+					p.errorf(start, "SYN021", annotation.Name)
+					break
+				}
+			}
+		}
+	}
+	annotation.Span = p.spanFrom(start)
+	return annotation
+}
+
+// parseElementValue analiza el valor de un elemento de una anotación: una
+// expresión, otra anotación o una lista de valores entre llaves
+func (p *Parser) parseElementValue() Expr {
+	switch {
+	case p.atAnnotation():
+		return p.parseAnnotation()
+	case p.at(KindLBrace):
+		open := p.next()
+		list := &ArrayInitializer{}
+		for !p.at(KindRBrace) {
+			list.Elems = append(list.Elems, p.parseElementValue())
+			if !p.accept(KindComma) {
+				break
+			}
+		}
+		p.expect(KindRBrace, "SYN022")
+		list.Span = p.spanFrom(open)
+		return list
+	}
+	return p.parseBinary(precOr)
+}
+
+// skipAnnotations devuelve la posición siguiente a las anotaciones que
+// empiezan en i, sin construir nodos
+func (p *Parser) skipAnnotations(i int) int {
+	for i+1 < len(p.tokens) && p.tokens[i].Type == KindAt && p.tokens[i+1].Type == KindIdentifier {
+		i += 2
+		for i+1 < len(p.tokens) && p.tokens[i].Type == KindDot && p.tokens[i+1].Type == KindIdentifier {
+			i += 2
+		}
+		if i < len(p.tokens) && p.tokens[i].Type == KindLParen {
+			depth := 0
+			for ; i < len(p.tokens); i++ {
+				if p.tokens[i].Type == KindLParen {
+					depth++
+				} else if p.tokens[i].Type == KindRParen {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+			i++
+		}
+	}
+	return i
+}
+
+// contextualModifierLength devuelve cuántos tokens ocupa el modificador
+// contextual "sealed" o "non-sealed" que empieza en i, o 0 si no hay ninguno.
+// Solo son modificadores si les sigue otro modificador o una declaración.
+func (p *Parser) contextualModifierLength(i int) int {
+	if i >= len(p.tokens) || p.tokens[i].Type != KindIdentifier {
+		return 0
+	}
+	n := 0
+	switch t := p.tokens[i]; {
+	case t.Value == "sealed":
+		n = 1
+	case t.Value == "non" && i+2 < len(p.tokens) && p.tokens[i+1].Type == OpSub &&
+		p.tokens[i+2].Value == "sealed" && p.tokens[i+1].Offset == t.EndOffset &&
+		p.tokens[i+2].Offset == p.tokens[i+1].EndOffset:
+		n = 3
+	default:
+		return 0
+	}
+	if i+n >= len(p.tokens) {
+		return 0
+	}
+	next := p.tokens[i+n]
+	if modifierKeywords[next.Type] || next.Type == KwClass || next.Type == KwInterface ||
+		p.contextualModifierLength(i+n) > 0 || next.Type == KindIdentifier && next.Value == "record" {
+		return n
+	}
+	return 0
+}
+
+// contextualModifierText une los tokens de un modificador contextual
+func contextualModifierText(tokens []Token) string {
+	text := ""
+	for _, token := range tokens {
+		text += token.Value
+	}
+	return text
+}
+
+// atTypeDeclStart indica si empieza la declaración de una clase, interfaz,
+// tipo de anotación, enum o record
+func (p *Parser) atTypeDeclStart() bool {
+	return p.at(KwClass) || p.at(KwInterface) || p.at(KwEnum) || p.atRecordStart(p.pos) ||
+		p.at(KindAt) && p.peekAt(1).Type == KwInterface
+}
+
+// atRecordStart indica si en i empieza "record Nombre(" o "record Nombre<";
+// "record" es una palabra clave contextual
+func (p *Parser) atRecordStart(i int) bool {
+	return i+2 < len(p.tokens) && p.tokens[i].Type == KindIdentifier && p.tokens[i].Value == "record" &&
+		p.tokens[i+1].Type == KindIdentifier && (p.tokens[i+2].Type == KindLParen || p.tokens[i+2].Type == OpLess)
+}
+
+// atLocalTypeDeclStart indica si empieza la declaración de una clase local,
+// con sus modificadores
+func (p *Parser) atLocalTypeDeclStart() bool {
+	i := p.skipAnnotations(p.pos)
+	for i < len(p.tokens) && (p.tokens[i].Type == KwAbstract || p.tokens[i].Type == KwFinal ||
+		p.tokens[i].Type == KwStatic || p.tokens[i].Type == KwStrictfp) {
+		i = p.skipAnnotations(i + 1)
+	}
+	if n := p.contextualModifierLength(i); n > 0 {
+		i += n
+	}
+	if i >= len(p.tokens) {
+		return false
+	}
+	switch p.tokens[i].Type {
+	case KwClass, KwInterface, KwEnum:
+		return true
+	case KindAt:
+		return i+1 < len(p.tokens) && p.tokens[i+1].Type == KwInterface
+	}
+	return p.atRecordStart(i)
+}
+
+// atMethodDeclStart indica si empieza la declaración de un método:
+// "void nombre(", "Tipo nombre(" o "<T> Tipo nombre("
+func (p *Parser) atMethodDeclStart() bool {
+	i := p.pos
+	if p.at(OpLess) {
+		// Método genérico: "<T> T nombre("
+		var ok bool
+		if i, ok = p.skipTypeArgs(i); !ok {
+			return false
+		}
+	}
+	if i < len(p.tokens) && p.tokens[i].Type == KwVoid {
+		i++
+	} else {
+		var ok bool
+		if i, ok = p.skipType(i); !ok {
+			return false
+		}
+	}
+	return i+1 < len(p.tokens) && p.tokens[i].Type == KindIdentifier && p.tokens[i+1].Type == KindLParen
+}
+
+// parseTypeDecl analiza la declaración de una clase, interfaz, enum o
+// record; los modificadores ya se consumieron a partir de start
+func (p *Parser) parseTypeDecl(start Token, modifiers Modifiers) *ClassDecl {
+	keyword := p.next()
+	decl := &ClassDecl{Modifiers: modifiers, Kind: keyword.Value}
+	if keyword.Type == KindAt {
+		p.next()
+		decl.Kind = "@interface"
+		keyword.Value = decl.Kind
+	}
+	decl.Name = p.expect(KindIdentifier, "SYN023", keyword.Value).Value
+	defer p.restoreTypeVars(len(p.typeVars))
+	// Un error en la cabecera no impide analizar los miembros
+	p.recoverHeader(func() { p.parseTypeHeader(start, decl) })
+
+	if decl.Kind == "enum" {
+		decl.Constants, decl.Members = p.parseEnumBody(decl.Name)
+	} else {
+		decl.Members = p.parseClassBody(decl.Name, decl.Kind)
+	}
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+// parseTypeHeader analiza los parámetros de tipo, los componentes de un
+// record y las cláusulas extends, implements y permits de un tipo
+func (p *Parser) parseTypeHeader(start Token, decl *ClassDecl) {
+	if p.at(OpLess) {
+		if decl.Kind == "enum" || decl.Kind == "@interface" {
+			p.fail(p.peek(), "SYN024", decl.Kind)
+		}
+		decl.TypeParams = p.parseTypeParams()
+	}
+	if decl.Kind == "record" {
+		decl.Components = p.parseParams(decl.Name)
+	}
+
+	if p.at(KwExtends) {
+		extends := p.next()
+		decl.Extends = p.parseTypeList()
+		switch {
+		case decl.Kind == "enum" || decl.Kind == "record" || decl.Kind == "@interface":
+			p.errorf(extends, "SYN025", decl.Kind)
+		case decl.Kind == "class" && len(decl.Extends) > 1:
+			p.errorf(p.prev(), "SYN026")
+		}
+	}
+	if p.at(KwImplements) {
+		implements := p.next()
+		if decl.Kind == "interface" {
+			p.errorf(implements, "SYN027")
+		} else if decl.Kind == "@interface" {
+			p.errorf(implements, "SYN028")
+		}
+		decl.Implements = p.parseTypeList()
+	}
+	if p.at(KindIdentifier) && p.peek().Value == "permits" {
+		permits := p.next()
+		if !decl.Modifiers.Has("sealed") {
+			p.errorf(permits, "SYN029")
+		}
+		decl.Permits = p.parseTypeList()
+	}
+	if decl.Modifiers.Has("abstract") && decl.Modifiers.Has("final") {
+		p.errorf(start, "SYN030", decl.Name)
+	}
+}
+
+// parseEnumBody analiza "{ A, B(1), C { ... }; miembros }"
+func (p *Parser) parseEnumBody(enumName string) ([]*EnumConstant, []Decl) {
+	open := p.expect(KindLBrace, "SYN031", enumName)
+	var constants []*EnumConstant
+	for p.at(KindIdentifier) || p.atAnnotation() {
+		start := p.peek()
+		annotations := p.parseAnnotations()
+		constant := &EnumConstant{Annotations: annotations}
+		constant.Name = p.expect(KindIdentifier, "SYN032").Value
+		if p.at(KindLParen) {
+			constant.Args = p.parseArguments()
+		}
+		if p.at(KindLBrace) {
+			constant.Body = p.parseClassBody(constant.Name, "class")
+		}
+		constant.Span = p.spanFrom(start)
+		constants = append(constants, constant)
+		if !p.accept(KindComma) {
+			break
+		}
+	}
+
+	if p.accept(KindRBrace) {
+		return constants, nil
+	}
+	if !p.accept(KindSemicolon) {
+		if p.atEnd() {
+			p.fail(open, "SYN007")
+		}
+		p.fail(p.peek(), "SYN033", enumName)
+	}
+	return constants, p.parseMembers(open, enumName, "enum")
+}
+
+// parseTypeList analiza "A, B, C"
+func (p *Parser) parseTypeList() []*TypeNode {
+	types := []*TypeNode{p.parseType()}
+	for p.accept(KindComma) {
+		types = append(types, p.parseType())
+	}
+	return types
+}
+
+// parseClassBody analiza los miembros entre llaves de una clase, interfaz o
+// record; kind indica cuál de ellos
+func (p *Parser) parseClassBody(className, kind string) []Decl {
+	open := p.expect(KindLBrace, "SYN031", className)
+	return p.parseMembers(open, className, kind)
+}
+
+// parseMembers analiza miembros hasta la '}' que cierra el cuerpo abierto en open
+func (p *Parser) parseMembers(open Token, className, kind string) []Decl {
+	var members []Decl
+	for !p.at(KindRBrace) {
+		if p.atEnd() {
+			p.errorf(open, "SYN007")
+			return members
+		}
+		bad := p.recover(func() {
+			if member := p.parseMember(className, kind); member != nil {
+				members = append(members, member)
+			}
+		})
+		if bad != nil {
+			members = append(members, bad)
+		}
+	}
+	p.next()
+	return members
+}
+
+// parseMember analiza un campo, método, constructor, bloque inicializador o
+// tipo anidado
+func (p *Parser) parseMember(className, kind string) Decl {
+	if p.accept(KindSemicolon) {
+		return nil
+	}
+	start := p.peek()
+	modifiers := p.parseModifiers()
+
+	switch {
+	case p.at(KindLBrace):
+		if len(modifiers.Annotations) > 0 || len(modifiers.Keywords) > 1 ||
+			len(modifiers.Keywords) == 1 && modifiers.Keywords[0] != "static" {
+			p.errorf(start, "SYN034")
+		}
+		block := &InitializerBlock{Static: modifiers.Has("static"), Body: p.parseBlock()}
+		block.Span = p.spanFrom(start)
+		return block
+	case p.atTypeDeclStart():
+		return p.parseTypeDecl(start, modifiers)
+	case kind == "record" && p.at(KindIdentifier) && p.peek().Value == className && p.peekAt(1).Type == KindLBrace:
+		// Constructor compacto: los parámetros son los componentes del record
+		p.next()
+		decl := &MethodDecl{Modifiers: modifiers, Name: className, Compact: true, Body: p.parseBlock()}
+		decl.Span = p.spanFrom(start)
+		return decl
+	case p.at(OpLess) && !p.atMethodDeclStart():
+		// Constructor genérico: "<T> Nombre(T valor)"
+		defer p.restoreTypeVars(len(p.typeVars))
+		typeParams := p.parseTypeParams()
+		if !p.at(KindIdentifier) || p.peek().Value != className || p.peekAt(1).Type != KindLParen {
+			p.fail(p.peek(), "SYN035")
+		}
+		decl := p.parseMethodDecl(start, modifiers, className)
+		decl.TypeParams = typeParams
+		return decl
+	case p.at(KindIdentifier) && p.peekAt(1).Type == KindLParen:
+		// Constructor: su nombre debe coincidir con el de la clase
+		if p.peek().Value != className {
+			p.fail(p.peek(), "SYN036", p.peek().Value)
+		}
+		if kind == "interface" || kind == "@interface" {
+			p.errorf(p.peek(), "SYN037")
+		}
+		return p.parseMethodDecl(start, modifiers, className)
+	case p.atMethodDeclStart():
+		decl := p.parseMethodDecl(start, modifiers, "")
+		switch {
+		case kind == "@interface" && len(decl.Params) > 0:
+			p.errorf(start, "SYN038", decl.Name)
+		case kind != "@interface" && decl.Default != nil:
+			p.errorf(start, "SYN039", decl.Name)
+		}
+		return decl
+	}
+
+	if _, ok := p.skipType(p.pos); !ok {
+		p.fail(p.peek(), "SYN040", className, describeToken(p.peek()))
+	}
+	decl := &FieldDecl{Modifiers: modifiers, Type: p.parseType()}
+	decl.Vars = p.parseVarDeclarators()
+	p.expectSemicolon(newPhrase("phrase.field_decl"))
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+// parseMethodDecl analiza un método o, si constructor no está vacío, el
+// constructor de esa clase
+func (p *Parser) parseMethodDecl(start Token, modifiers Modifiers, constructor string) *MethodDecl {
+	decl := &MethodDecl{Modifiers: modifiers}
+	if p.at(OpLess) {
+		defer p.restoreTypeVars(len(p.typeVars))
+		decl.TypeParams = p.parseTypeParams()
+	}
+	if constructor == "" {
+		decl.ResultType = p.parseResultType()
+	}
+	decl.Name = p.expect(KindIdentifier, "").Value
+	// Un error en los parámetros no impide analizar el cuerpo
+	p.recoverHeader(func() {
+		decl.Params = p.parseParams(decl.Name)
+		if dims, annotations := p.parseDims(); dims > 0 && decl.ResultType != nil {
+			decl.ResultType.Dims += dims
+			decl.ResultType.DimAnnotations = append(decl.ResultType.DimAnnotations, annotations...)
+		}
+		if p.accept(KwThrows) {
+			decl.Throws = p.parseTypeList()
+		}
+		if p.accept(KwDefault) {
+			decl.Default = p.parseElementValue()
+		}
+	})
+
+	if p.at(KindLBrace) {
+		decl.Body = p.parseBlock()
+	} else {
+		p.expectSemicolon(newPhrase("phrase.method_decl", decl.Name))
+	}
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+// parseParams analiza la lista de parámetros formales entre paréntesis
+func (p *Parser) parseParams(method string) []*Param {
+	p.expect(KindLParen, "")
+	var params []*Param
+	if p.accept(KindRParen) {
+		return params
+	}
+	for {
+		start := p.peek()
+		param := &Param{Modifiers: p.parseModifiers(), Type: p.parseType()}
+		if p.accept(KindEllipsis) {
+			param.Varargs = true
+		}
+		param.Name = p.expect(KindIdentifier, "SYN041", method).Value
+		dims, annotations := p.parseDims()
+		param.Type.Dims += dims
+		param.Type.DimAnnotations = append(param.Type.DimAnnotations, annotations...)
+		param.Span = p.spanFrom(start)
+		if len(params) > 0 && params[len(params)-1].Varargs {
+			p.errorf(start, "SYN042", method)
+		}
+		params = append(params, param)
+		if !p.accept(KindComma) {
+			break
+		}
+	}
+	p.expect(KindRParen, "SYN043", method)
+	return params
+}
+
+// parseVarDeclarators analiza "a = 1, b, c[] = null"
+func (p *Parser) parseVarDeclarators() []*VarDeclarator {
+	var vars []*VarDeclarator
+	for {
+		name := p.expect(KindIdentifier, "SYN044")
+		v := &VarDeclarator{Name: name.Value}
+		v.Dims, v.DimAnnotations = p.parseDims()
+		if p.accept(OpAssign) {
+			v.Init = p.parseVariableInit()
+		}
+		v.Span = p.spanFrom(name)
+		vars = append(vars, v)
+		if !p.accept(KindComma) {
+			return vars
+		}
+	}
+}
+
+// parseVariableInit analiza el valor inicial de una variable: una expresión
+// o un inicializador de array
+func (p *Parser) parseVariableInit() Expr {
+	if p.at(KindLBrace) {
+		return p.parseArrayInitializer()
+	}
+	return p.parseExpression()
+}
@@ -0,0 +1,198 @@
+// analyzer/diagnostics.go
+package analyzer
+
+import "sort"
+
+// Severity gravedad de un diagnóstico
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+	SeverityHint    Severity = "hint"
+)
+
+// Phase fase del análisis que produce un diagnóstico
+type Phase string
+
+const (
+	PhaseLexical  Phase = "lexical"
+	PhaseSyntax   Phase = "syntax"
+	PhaseSemantic Phase = "semantic"
+)
+
+// codePhases fase de cada prefijo de código
+var codePhases = map[string]Phase{
+	"LEX": PhaseLexical,
+	"SYN": PhaseSyntax,
+	"SEM": PhaseSemantic,
+}
+
+// Label posición secundaria de un diagnóstico, con el texto que explica su
+// relación con la principal
+type Label struct {
+	Span    Span
+	Message string
+
+	key  string
+	args []interface{}
+}
+
+// Diagnostic problema encontrado en el código. Code identifica el tipo de
+// problema y no cambia entre versiones; Message es el texto del catálogo con
+// Args ya sustituidos, en el idioma del diagnóstico. Rule es la regla
+// configurable que lo produce, si la hay.
+type Diagnostic struct {
+	Code     string
+	Rule     string `json:",omitempty"`
+	Severity Severity
+	Phase    Phase
+	Span     Span
+	Message  string
+	Args     []string `json:",omitempty"`
+	Labels   []Label  `json:",omitempty"`
+	Notes    []string `json:",omitempty"`
+
+	locale string        // idioma de Message, Labels y Notes
+	args   []interface{} // argumentos sin traducir, para cambiar de idioma
+	notes  []phrase
+}
+
+// newDiagnostic crea el diagnóstico code situado en at, con el mensaje del
+// catálogo formateado con args en el idioma por defecto
+func newDiagnostic(code string, severity Severity, at Node, args ...interface{}) Diagnostic {
+	d := Diagnostic{
+		Code:     code,
+		Severity: severity,
+		Phase:    codePhases[code[:3]],
+		Span:     at.Range(),
+		args:     args,
+	}
+	d.render(DefaultLocale)
+	return d
+}
+
+// addLabel señala una posición relacionada con el diagnóstico, con el
+// mensaje key del catálogo
+func (d *Diagnostic) addLabel(at Node, key string, args ...interface{}) {
+	d.Labels = append(d.Labels, Label{
+		Span:    at.Range(),
+		Message: translate(d.locale, key, args),
+		key:     key,
+		args:    args,
+	})
+}
+
+// addNote añade una explicación al diagnóstico, con el mensaje key del catálogo
+func (d *Diagnostic) addNote(key string, args ...interface{}) {
+	note := newPhrase(key, args...)
+	d.notes = append(d.notes, note)
+	d.Notes = append(d.Notes, translate(d.locale, note.key, note.args))
+}
+
+// render escribe los textos del diagnóstico en el idioma locale
+func (d *Diagnostic) render(locale string) {
+	d.locale = locale
+	d.Message = translate(locale, d.Code, d.args)
+	d.Args = nil
+	for _, arg := range d.args {
+		d.Args = append(d.Args, renderArg(locale, arg))
+	}
+	labels := d.Labels
+	d.Labels = nil
+	for _, label := range labels {
+		label.Message = translate(locale, label.key, label.args)
+		d.Labels = append(d.Labels, label)
+	}
+	d.Notes = nil
+	for _, note := range d.notes {
+		d.Notes = append(d.Notes, translate(locale, note.key, note.args))
+	}
+}
+
+// Localize devuelve una copia de los diagnósticos con los mensajes en el
+// idioma locale
+func Localize(diagnostics []Diagnostic, locale string) []Diagnostic {
+	localized := make([]Diagnostic, len(diagnostics))
+	for i, d := range diagnostics {
+		d.render(locale)
+		localized[i] = d
+	}
+	return localized
+}
+
+// String devuelve el diagnóstico en el formato de texto de las versiones
+// anteriores, como "Error línea 3: Variable 'x' usada sin declarar"
+func (d Diagnostic) String() string {
+	return translate(d.locale, "format."+string(d.Severity), []interface{}{d.Span.Line, d.Message})
+}
+
+// Messages devuelve los diagnósticos en el formato de texto
+func Messages(diagnostics []Diagnostic) []string {
+	texts := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		texts[i] = d.String()
+	}
+	return texts
+}
+
+// SortDiagnostics ordena los diagnósticos por su posición en el código. Los
+// que empiezan en la misma posición conservan el orden en que se produjeron.
+func SortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		a, b := diagnostics[i].Span, diagnostics[j].Span
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Col < b.Col
+	})
+}
+
+// HasErrors indica si algún diagnóstico es un error; las advertencias y
+// sugerencias no impiden compilar el programa
+func HasErrors(diagnostics []Diagnostic) bool {
+	for _, d := range diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// LexicalDiagnostics devuelve los errores léxicos de los tokens: caracteres
+// no válidos y literales o comentarios mal formados
+func LexicalDiagnostics(tokens []Token) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, token := range tokens {
+		if token.Diagnostic != nil {
+			diagnostics = append(diagnostics, *token.Diagnostic)
+		}
+	}
+	return diagnostics
+}
+
+// lexicalError crea el token de un literal o comentario mal formado. Su valor
+// es el mensaje del error; la posición la completa el Scanner.
+func lexicalError(code string, line, col int, raw string, args ...interface{}) *Token {
+	diagnostic := newDiagnostic(code, SeverityError, Span{}, args...)
+	return &Token{Type: KindError, Value: diagnostic.Message, Line: line, Col: col, Raw: raw, Diagnostic: &diagnostic}
+}
+
+// LocalizeTokens devuelve una copia de los tokens con los errores léxicos en
+// el idioma locale
+func LocalizeTokens(tokens []Token, locale string) []Token {
+	localized := make([]Token, len(tokens))
+	for i, token := range tokens {
+		if token.Diagnostic != nil {
+			d := *token.Diagnostic
+			d.render(locale)
+			token.Diagnostic = &d
+			if token.Type == KindError {
+				token.Value = d.Message
+			}
+		}
+		localized[i] = token
+	}
+	return localized
+}
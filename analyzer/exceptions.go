@@ -0,0 +1,207 @@
+// analyzer/exceptions.go
+package analyzer
+
+import "strings"
+
+// exceptionParents superclase de las excepciones más usadas de la JDK, para
+// decidir si un catch es inalcanzable o un multi-catch redundante
+var exceptionParents = map[string]string{
+	"Exception": "Throwable",
+	"Error":     "Throwable",
+
+	"RuntimeException":             "Exception",
+	"IOException":                  "Exception",
+	"InterruptedException":         "Exception",
+	"CloneNotSupportedException":   "Exception",
+	"ReflectiveOperationException": "Exception",
+	"TimeoutException":             "Exception",
+	"ExecutionException":           "Exception",
+	"SQLException":                 "Exception",
+	"URISyntaxException":           "Exception",
+	"ParseException":               "Exception",
+
+	"ClassNotFoundException":    "ReflectiveOperationException",
+	"NoSuchMethodException":     "ReflectiveOperationException",
+	"NoSuchFieldException":      "ReflectiveOperationException",
+	"InstantiationException":    "ReflectiveOperationException",
+	"IllegalAccessException":    "ReflectiveOperationException",
+	"InvocationTargetException": "ReflectiveOperationException",
+
+	"FileNotFoundException":        "IOException",
+	"EOFException":                 "IOException",
+	"MalformedURLException":        "IOException",
+	"UnsupportedEncodingException": "IOException",
+	"NoSuchFileException":          "FileSystemException",
+	"FileSystemException":          "IOException",
+
+	"ArithmeticException":             "RuntimeException",
+	"ArrayStoreException":             "RuntimeException",
+	"ClassCastException":              "RuntimeException",
+	"IllegalArgumentException":        "RuntimeException",
+	"IllegalStateException":           "RuntimeException",
+	"IndexOutOfBoundsException":       "RuntimeException",
+	"NegativeArraySizeException":      "RuntimeException",
+	"NullPointerException":            "RuntimeException",
+	"UnsupportedOperationException":   "RuntimeException",
+	"ConcurrentModificationException": "RuntimeException",
+	"NoSuchElementException":          "RuntimeException",
+	"UncheckedIOException":            "RuntimeException",
+	"SecurityException":               "RuntimeException",
+	"DateTimeException":               "RuntimeException",
+
+	"NumberFormatException":           "IllegalArgumentException",
+	"PatternSyntaxException":          "IllegalArgumentException",
+	"InputMismatchException":          "NoSuchElementException",
+	"ArrayIndexOutOfBoundsException":  "IndexOutOfBoundsException",
+	"StringIndexOutOfBoundsException": "IndexOutOfBoundsException",
+	"DateTimeParseException":          "DateTimeException",
+
+	"AssertionError":              "Error",
+	"VirtualMachineError":         "Error",
+	"OutOfMemoryError":            "VirtualMachineError",
+	"StackOverflowError":          "VirtualMachineError",
+	"LinkageError":                "Error",
+	"NoClassDefFoundError":        "LinkageError",
+	"ExceptionInInitializerError": "LinkageError",
+}
+
+// simpleTypeName quita el paquete y los argumentos genéricos de un nombre
+// de tipo: "java.io.IOException", "java.util.List<String>"
+func simpleTypeName(name string) string {
+	name = rawType(name)
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// superclassOf devuelve la superclase conocida de una excepción, de la
+// JDK o declarada en el código analizado
+func (c *checker) superclassOf(name string) (string, bool) {
+	name = simpleTypeName(name)
+	if decl, ok := c.types[name]; ok {
+		if decl.Kind != "class" || len(decl.Extends) == 0 {
+			return "Object", true
+		}
+		return simpleTypeName(decl.Extends[0].Name), true
+	}
+	if name == "Throwable" {
+		return "Object", true
+	}
+	parent, ok := exceptionParents[name]
+	return parent, ok
+}
+
+// isSubclassOf indica si sub es sup o una subclase conocida de sup
+func (c *checker) isSubclassOf(sub, sup string) bool {
+	sub, sup = simpleTypeName(sub), simpleTypeName(sup)
+	// Cada paso sube un nivel; el límite evita ciclos en clases mal declaradas
+	for depth := 0; depth < 32; depth++ {
+		if sub == sup {
+			return true
+		}
+		parent, ok := c.superclassOf(sub)
+		if !ok || parent == "Object" {
+			return false
+		}
+		sub = parent
+	}
+	return false
+}
+
+// isThrowableType indica si el tipo es una excepción. Los tipos cuya
+// jerarquía se desconoce se aceptan.
+func (c *checker) isThrowableType(name string) bool {
+	if name == typeUnknown || name == typeNull {
+		return true
+	}
+	if isPrimitiveType(name) || isKnownReferenceType(name) {
+		return false
+	}
+	name = simpleTypeName(name)
+	for depth := 0; depth < 32; depth++ {
+		if name == "Throwable" {
+			return true
+		}
+		parent, known := c.superclassOf(name)
+		if !known {
+			return true
+		}
+		if parent == "Object" {
+			return false
+		}
+		name = parent
+	}
+	return true
+}
+
+// resourceUse variable existente usada como recurso de un try
+type resourceUse struct {
+	sym *symbol
+	pos Span
+}
+
+// checkTry analiza un try: sus recursos, su cuerpo y sus catch
+func (c *checker) checkTry(stmt *TryStmt) {
+	c.withScope(func() {
+		for _, resource := range stmt.Resources {
+			switch r := resource.(type) {
+			case *LocalVarDecl:
+				// Los recursos declarados en el try son implícitamente final
+				c.checkLocalVarDecl(r)
+				if sym := c.scope.vars[r.Vars[0].Name]; sym != nil {
+					sym.final = true
+				}
+			case Expr:
+				c.typeOf(r)
+				if id, ok := r.(*Ident); ok {
+					if sym := c.scope.lookup(id.Name); sym != nil {
+						c.resources = append(c.resources, resourceUse{sym: sym, pos: id.Span})
+					}
+				}
+			}
+		}
+		c.checkStmt(stmt.Body)
+	})
+
+	// Tipos ya capturados por los catch anteriores
+	var caught []string
+	for _, clause := range stmt.Catches {
+		for i, node := range clause.Types {
+			typ := simpleTypeName(typeName(node, 0))
+			if !c.isThrowableType(typ) {
+				c.errorf(node, "SEM070", typ)
+				continue
+			}
+			for _, other := range clause.Types[:i] {
+				other := simpleTypeName(typeName(other, 0))
+				if c.isSubclassOf(typ, other) || c.isSubclassOf(other, typ) {
+					sub, sup := typ, other
+					if c.isSubclassOf(other, typ) {
+						sub, sup = other, typ
+					}
+					c.errorf(node, "SEM071", sub, sup)
+				}
+			}
+			for _, previous := range caught {
+				if c.isSubclassOf(typ, previous) {
+					c.errorf(node, "SEM072", typ, previous)
+					break
+				}
+			}
+		}
+		for _, node := range clause.Types {
+			caught = append(caught, simpleTypeName(typeName(node, 0)))
+		}
+
+		c.withScope(func() {
+			typ := typeName(clause.Types[0], 0)
+			if len(clause.Types) > 1 {
+				typ = typeUnknown
+			}
+			// El parámetro de un multi-catch es implícitamente final
+			c.declare(clause.Name, typ, clause, nil).final = clause.Modifiers.Has("final") || len(clause.Types) > 1
+			c.checkStmt(clause.Body)
+		})
+	}
+	if stmt.Finally != nil {
+		c.checkStmt(stmt.Finally)
+	}
+}
@@ -0,0 +1,593 @@
+// analyzer/expressions.go
+package analyzer
+
+// Niveles de precedencia de los operadores de Java, de menor a mayor
+const (
+	precAssignment = iota + 1
+	precConditional
+	precOr
+	precAnd
+	precBitOr
+	precXor
+	precBitAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+)
+
+// binaryPrecedence precedencia de cada operador binario; todos son
+// asociativos por la izquierda
+var binaryPrecedence = map[TokenKind]int{
+	OpOr:                 precOr,
+	OpAnd:                precAnd,
+	OpBitOr:              precBitOr,
+	OpXor:                precXor,
+	OpBitAnd:             precBitAnd,
+	OpEqual:              precEquality,
+	OpNotEqual:           precEquality,
+	OpLess:               precRelational,
+	OpLessEqual:          precRelational,
+	OpGreater:            precRelational,
+	OpGreaterEqual:       precRelational,
+	OpShiftLeft:          precShift,
+	OpShiftRight:         precShift,
+	OpUnsignedShiftRight: precShift,
+	OpAdd:                precAdditive,
+	OpSub:                precAdditive,
+	OpMul:                precMultiplicative,
+	OpDiv:                precMultiplicative,
+	OpMod:                precMultiplicative,
+}
+
+// assignmentOperators operadores de asignación simple y compuesta
+var assignmentOperators = map[TokenKind]bool{
+	OpAssign: true, OpAddAssign: true, OpSubAssign: true, OpMulAssign: true,
+	OpDivAssign: true, OpModAssign: true, OpBitAndAssign: true, OpBitOrAssign: true,
+	OpXorAssign: true, OpShiftLeftAssign: true, OpShiftRightAssign: true,
+	OpUnsignedShiftRightAssign: true,
+}
+
+// prefixOperators operadores unarios prefijos
+var prefixOperators = map[TokenKind]bool{
+	OpAdd: true, OpSub: true, OpNot: true, OpBitNot: true,
+	OpIncrement: true, OpDecrement: true,
+}
+
+// literalKinds tipos de token que forman un literal
+var literalKinds = map[TokenKind]bool{
+	KindNumber: true, KindLong: true, KindFloat: true, KindDouble: true,
+	KindString: true, KindChar: true, KwTrue: true, KwFalse: true, KwNull: true,
+	KindError: true,
+}
+
+// parseExpression analiza una expresión completa
+func (p *Parser) parseExpression() Expr {
+	return p.parseBinary(precAssignment)
+}
+
+// parseBinary analiza operaciones binarias, condicionales, instanceof y
+// asignaciones cuya precedencia es al menos minPrec (análisis de Pratt)
+func (p *Parser) parseBinary(minPrec int) Expr {
+	start := p.peek()
+	left := p.parseUnary()
+	for {
+		op := p.peek()
+		switch {
+		case assignmentOperators[op.Type]:
+			if minPrec > precAssignment {
+				return left
+			}
+			if !isAssignable(left) {
+				p.errorf(op, "SYN078", op.Value)
+			}
+			p.next()
+			// La asignación es asociativa por la derecha
+			value := p.parseBinary(precAssignment)
+			left = &AssignExpr{Span: p.spanFrom(start), Op: op.Type, Target: left, Value: value}
+
+		case op.Type == OpQuestion:
+			if minPrec > precConditional {
+				return left
+			}
+			p.next()
+			then := p.parseExpression()
+			p.expect(OpColon, "SYN079")
+			// El condicional también es asociativo por la derecha
+			els := p.parseBinary(precConditional)
+			left = &ConditionalExpr{Span: p.spanFrom(start), Cond: left, Then: then, Else: els}
+
+		case op.Type == KwInstanceof:
+			if minPrec > precRelational {
+				return left
+			}
+			p.next()
+			p.accept(KwFinal)
+			expr := &InstanceOfExpr{X: left, Type: p.parseType()}
+			if p.at(KindIdentifier) {
+				expr.Binding = p.next().Value
+			}
+			expr.Span = p.spanFrom(start)
+			left = expr
+
+		default:
+			prec, ok := binaryPrecedence[op.Type]
+			if !ok || prec < minPrec {
+				return left
+			}
+			p.next()
+			right := p.parseBinary(prec + 1)
+			left = &BinaryExpr{Span: p.spanFrom(start), Op: op.Type, X: left, Y: right}
+		}
+	}
+}
+
+// parseUnary analiza los operadores prefijos, las conversiones de tipo y las lambdas
+func (p *Parser) parseUnary() Expr {
+	start := p.peek()
+	switch {
+	case prefixOperators[start.Type]:
+		p.next()
+		x := p.parseUnary()
+		if (start.Type == OpIncrement || start.Type == OpDecrement) && !isAssignable(x) {
+			p.errorf(start, "SYN080", start.Value)
+		}
+		return &UnaryExpr{Span: p.spanFrom(start), Op: start.Type, X: x}
+	case p.atLambda():
+		return p.parseLambda()
+	case p.atCast():
+		p.next()
+		typ := p.parseType()
+		p.expect(KindRParen, "SYN081")
+		x := p.parseUnary()
+		return &CastExpr{Span: p.spanFrom(start), Type: typ, X: x}
+	}
+	return p.parsePostfix(p.parsePrimary())
+}
+
+// atCast indica si el '(' actual abre una conversión de tipo. Un tipo
+// primitivo entre paréntesis siempre lo es; un nombre solo si va seguido de
+// algo que no pueda continuar una expresión entre paréntesis.
+func (p *Parser) atCast() bool {
+	if !p.at(KindLParen) {
+		return false
+	}
+	i, ok := p.skipType(p.pos + 1)
+	if !ok || i >= len(p.tokens) || p.tokens[i].Type != KindRParen {
+		return false
+	}
+	if primitiveTypes[p.tokens[p.pos+1].Type] {
+		return true
+	}
+	if i+1 >= len(p.tokens) {
+		return false
+	}
+	switch next := p.tokens[i+1]; {
+	case next.Type == KindIdentifier, literalKinds[next.Type] && next.Type != KindError:
+		return true
+	case next.Type == KindLParen, next.Type == OpNot, next.Type == OpBitNot:
+		return true
+	case next.Type == KwThis, next.Type == KwSuper, next.Type == KwNew:
+		return true
+	}
+	return false
+}
+
+// atLambda indica si empieza una lambda: "x ->" o "( ... ) ->"
+func (p *Parser) atLambda() bool {
+	if p.caseLabel {
+		return false
+	}
+	if p.at(KindIdentifier) {
+		return p.peekAt(1).Type == OpArrow
+	}
+	if !p.at(KindLParen) {
+		return false
+	}
+	depth := 0
+	for i := p.pos; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case KindLParen:
+			depth++
+		case KindRParen:
+			depth--
+			if depth == 0 {
+				return i+1 < len(p.tokens) && p.tokens[i+1].Type == OpArrow
+			}
+		case KindSemicolon, KindLBrace, KindRBrace:
+			return false
+		}
+	}
+	return false
+}
+
+// parseLambda analiza "x -> ...", "(a, b) -> ..." o "(int a, int b) -> ..."
+func (p *Parser) parseLambda() Expr {
+	start := p.peek()
+	lambda := &LambdaExpr{}
+	if p.at(KindIdentifier) {
+		name := p.next()
+		lambda.Params = []*Param{{Span: tokenSpan(name), Name: name.Value}}
+	} else {
+		p.next()
+		for !p.at(KindRParen) {
+			lambda.Params = append(lambda.Params, p.parseLambdaParam())
+			if !p.accept(KindComma) {
+				break
+			}
+		}
+		p.expect(KindRParen, "SYN082")
+	}
+
+	explicit := 0
+	for _, param := range lambda.Params {
+		if param.Type != nil {
+			explicit++
+		}
+	}
+	if explicit > 0 && explicit < len(lambda.Params) {
+		p.errorf(start, "SYN083")
+	}
+
+	p.expect(OpArrow, "")
+	if p.at(KindLBrace) {
+		lambda.Body = p.parseBlock()
+	} else {
+		lambda.Body = p.parseExpression()
+	}
+	lambda.Span = p.spanFrom(start)
+	return lambda
+}
+
+// parseLambdaParam analiza un parámetro de lambda, con o sin tipo
+func (p *Parser) parseLambdaParam() *Param {
+	start := p.peek()
+	if p.at(KindIdentifier) && (p.peekAt(1).Type == KindComma || p.peekAt(1).Type == KindRParen) {
+		p.next()
+		return &Param{Span: tokenSpan(start), Name: start.Value}
+	}
+	param := &Param{Modifiers: p.parseModifiers(), Type: p.parseType()}
+	if p.accept(KindEllipsis) {
+		param.Varargs = true
+	}
+	param.Name = p.expect(KindIdentifier, "SYN084").Value
+	param.Span = p.spanFrom(start)
+	return param
+}
+
+// parsePostfix analiza accesos a campos y elementos, llamadas, referencias a
+// métodos e incrementos postfijos
+func (p *Parser) parsePostfix(x Expr) Expr {
+	start := x.Range()
+	for {
+		switch p.peek().Type {
+		case KindDot:
+			p.next()
+			if p.at(KwClass) {
+				p.next()
+				x = &ClassLiteral{Type: typeFromExpr(x)}
+				break
+			}
+			if p.at(KwNew) {
+				// Creación de una clase interna: outer.new Inner()
+				newStart := p.peek()
+				created, ok := p.parseNew().(*NewExpr)
+				if !ok {
+					p.fail(newStart, "SYN113")
+				}
+				created.Outer = x
+				x = created
+				break
+			}
+			if p.at(OpLess) {
+				// Argumentos genéricos explícitos: Collections.<String>emptyList()
+				typeArgs := p.parseTypeArgList()
+				name := p.expect(KindIdentifier, "SYN085")
+				if !p.at(KindLParen) {
+					p.fail(p.peek(), "SYN086", name.Value)
+				}
+				x = &MethodCall{Target: x, TypeArgs: typeArgs, Name: name.Value, Args: p.parseArguments()}
+				break
+			}
+			name := p.expect(KindIdentifier, "SYN087")
+			if p.at(KindLParen) {
+				x = &MethodCall{Target: x, Name: name.Value, Args: p.parseArguments()}
+			} else {
+				x = &FieldAccess{X: x, Name: name.Value}
+			}
+		case KindLBracket:
+			if name := qualifiedName(x); name != "" && p.atDim(p.pos) {
+				// Literal de clase de un array: String[].class
+				typ := typeFromExpr(x)
+				typ.Dims, typ.DimAnnotations = p.parseDims()
+				name = typeName(typ, 0)
+				p.expect(KindDot, "SYN092", name)
+				p.expect(KwClass, "SYN092", name)
+				x = &ClassLiteral{Type: typ}
+				break
+			}
+			p.next()
+			index := p.parseExpression()
+			p.expect(KindRBracket, "SYN088")
+			x = &ArrayAccess{X: x, Index: index}
+		case KindDoubleColon:
+			p.next()
+			ref := &MethodRef{X: x}
+			if p.at(KwNew) {
+				ref.Name = p.next().Value
+			} else {
+				ref.Name = p.expect(KindIdentifier, "SYN089").Value
+			}
+			x = ref
+		case OpIncrement, OpDecrement:
+			op := p.peek()
+			if p.startsOperand(p.peekAt(1)) && op.Line == p.peekAt(1).Line {
+				// "texto" ++ variable: se quiso concatenar
+				p.errorf(op, "SYN090", op.Value)
+				p.next()
+				right := p.parseBinary(precAdditive + 1)
+				x = &BinaryExpr{Op: OpAdd, X: x, Y: right}
+				break
+			}
+			if !isAssignable(x) {
+				p.errorf(op, "SYN080", op.Value)
+			}
+			p.next()
+			x = &UnaryExpr{Op: op.Type, X: x, Postfix: true}
+		default:
+			return x
+		}
+		setSpan(x, Span{
+			Line: start.Line, Col: start.Col, Offset: start.Offset, Offset16: start.Offset16,
+			EndLine: p.prev().EndLine, EndCol: p.prev().EndCol,
+			EndOffset: p.prev().EndOffset, EndOffset16: p.prev().EndOffset16,
+		})
+	}
+}
+
+// parsePrimary analiza literales, nombres, this, super, new y paréntesis
+func (p *Parser) parsePrimary() Expr {
+	start := p.peek()
+	switch {
+	case literalKinds[start.Type]:
+		p.next()
+		return &Literal{Span: tokenSpan(start), Kind: start.Type, Value: start.Value, Raw: start.Raw}
+	case start.Type == KindIdentifier && p.atTypeMethodRef():
+		return p.parseTypeMethodRef()
+	case start.Type == KindIdentifier:
+		p.next()
+		if p.at(KindLParen) {
+			return &MethodCall{Name: start.Value, Args: p.parseArguments(), Span: p.spanFrom(start)}
+		}
+		return &Ident{Span: tokenSpan(start), Name: start.Value}
+	case start.Type == KwThis || start.Type == KwSuper:
+		p.next()
+		if p.at(KindLParen) {
+			// Llamada explícita a un constructor: "this(...)" o "super(...)"
+			return &ConstructorCall{Super: start.Type == KwSuper, Args: p.parseArguments(), Span: p.spanFrom(start)}
+		}
+		if start.Type == KwSuper {
+			return &SuperExpr{Span: tokenSpan(start)}
+		}
+		return &ThisExpr{Span: tokenSpan(start)}
+	case start.Type == KwNew:
+		return p.parseNew()
+	case start.Type == KwSwitch:
+		selector, cases := p.parseSwitch(true)
+		return &SwitchExpr{Span: p.spanFrom(start), Selector: selector, Cases: cases}
+	case primitiveTypes[start.Type] && p.atTypeMethodRef():
+		return p.parseTypeMethodRef()
+	case primitiveTypes[start.Type] || start.Type == KwVoid:
+		// int.class, void.class. Sin '.class' el tipo no se consume, para que
+		// la recuperación pueda empezar en él una declaración.
+		i := p.pos + 1
+		for i+1 < len(p.tokens) && p.tokens[i].Type == KindLBracket && p.tokens[i+1].Type == KindRBracket {
+			i += 2
+		}
+		if i >= len(p.tokens) || p.tokens[i].Type != KindDot {
+			p.fail(start, "SYN091", describeToken(start))
+		}
+		typ := p.parseResultType()
+		p.expect(KindDot, "SYN091", describeToken(start))
+		p.expect(KwClass, "SYN092", typ.Name)
+		return &ClassLiteral{Span: p.spanFrom(start), Type: typ}
+	case start.Type == KindLBrace:
+		p.errorf(start, "SYN093")
+		return p.parseArrayInitializer()
+	case start.Type == KindLParen:
+		p.next()
+		x := p.parseExpression()
+		p.expect(KindRParen, "SYN094")
+		return &ParenExpr{Span: p.spanFrom(start), X: x}
+	}
+
+	switch start.Type {
+	case KindSemicolon, KindRParen, KindRBracket, KindRBrace, KindComma:
+		// Falta el operando pero la construcción sigue: "x + ;" o "f(a, )"
+		p.errorf(start, "SYN091", describeToken(start))
+		return &ErrorNode{Span: tokenSpan(start)}
+	}
+	p.fail(start, "SYN091", describeToken(start))
+	return nil
+}
+
+// atTypeMethodRef indica si empieza una referencia a método sobre un tipo
+// genérico o de array, que no puede leerse como expresión: "ArrayList<String>::new"
+// o "int[]::new"
+func (p *Parser) atTypeMethodRef() bool {
+	i, ok := p.skipType(p.pos)
+	if !ok || i >= len(p.tokens) || p.tokens[i].Type != KindDoubleColon {
+		return false
+	}
+	for _, token := range p.tokens[p.pos:i] {
+		if token.Type == OpLess || token.Type == KindLBracket {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTypeMethodRef analiza "Tipo::nombre" cuando Tipo es genérico o un array
+func (p *Parser) parseTypeMethodRef() Expr {
+	start := p.peek()
+	ref := &MethodRef{Type: p.parseType()}
+	p.expect(KindDoubleColon, "")
+	if p.at(KwNew) {
+		ref.Name = p.next().Value
+	} else {
+		ref.Name = p.expect(KindIdentifier, "SYN089").Value
+	}
+	ref.Span = p.spanFrom(start)
+	return ref
+}
+
+// parseTypeArgList analiza argumentos genéricos explícitos, que no admiten '<>'
+func (p *Parser) parseTypeArgList() []*TypeNode {
+	args, diamond := p.parseTypeArgs()
+	if diamond {
+		p.errorf(p.prev(), "SYN011")
+	}
+	return args
+}
+
+// parseNew analiza "new T(args)" con el cuerpo opcional de una clase anónima.
+// Solo aquí se admite el operador diamante: new ArrayList<>()
+func (p *Parser) parseNew() Expr {
+	start := p.next()
+	typ := p.parseTypeNode()
+	if typ.Dims > 0 || p.at(KindLBracket) {
+		return p.parseNewArray(start, typ)
+	}
+	expr := &NewExpr{Type: typ}
+	if !p.at(KindLParen) {
+		p.fail(p.peek(), "SYN095", expr.Type.Name)
+	}
+	expr.Args = p.parseArguments()
+	if p.at(KindLBrace) {
+		expr.Body = p.parseClassBody(expr.Type.Name, "class")
+	}
+	expr.Span = p.spanFrom(start)
+	return expr
+}
+
+// parseNewArray analiza el resto de "new int[5][]" o "new int[]{1, 2}";
+// typ ya incluye las dimensiones vacías que siguen al tipo
+func (p *Parser) parseNewArray(start Token, typ *TypeNode) Expr {
+	expr := &NewArrayExpr{Type: typ}
+	if typ.Diamond || len(typ.Args) > 0 || typ.TypeVar {
+		p.errorf(start, "SYN096", typeArgName(typ))
+	}
+	// Tras el tipo, parseDims ya consumió los "[]"; quedan las dimensiones con tamaño
+	for typ.Dims == 0 && p.at(KindLBracket) {
+		p.next()
+		expr.Lengths = append(expr.Lengths, p.parseExpression())
+		p.expect(KindRBracket, "SYN097")
+		typ.Dims, typ.DimAnnotations = p.parseDims()
+	}
+	if p.at(KindLBracket) {
+		p.fail(p.peek(), "SYN098")
+	}
+	typ.Dims += len(expr.Lengths)
+
+	switch {
+	case p.at(KindLBrace) && len(expr.Lengths) > 0:
+		p.errorf(p.peek(), "SYN099")
+		expr.Init = p.parseArrayInitializer()
+	case p.at(KindLBrace):
+		expr.Init = p.parseArrayInitializer()
+	case len(expr.Lengths) == 0:
+		p.fail(p.prev(), "SYN100", typeArgName(typ))
+	}
+	expr.Span = p.spanFrom(start)
+	return expr
+}
+
+// parseArrayInitializer analiza "{1, 2, 3}", con inicializadores anidados y
+// una coma final opcional
+func (p *Parser) parseArrayInitializer() *ArrayInitializer {
+	open := p.expect(KindLBrace, "")
+	init := &ArrayInitializer{}
+	for !p.at(KindRBrace) {
+		if p.at(KindLBrace) {
+			init.Elems = append(init.Elems, p.parseArrayInitializer())
+		} else {
+			init.Elems = append(init.Elems, p.parseExpression())
+		}
+		if !p.accept(KindComma) {
+			break
+		}
+	}
+	if !p.at(KindRBrace) {
+		p.fail(open, "SYN101")
+	}
+	p.next()
+	init.Span = p.spanFrom(open)
+	return init
+}
+
+// parseArguments analiza "(a, b, c)"
+func (p *Parser) parseArguments() []Expr {
+	open := p.expect(KindLParen, "")
+	// Dentro de los argumentos vuelve a haber lambdas, también en un case
+	caseLabel := p.caseLabel
+	p.caseLabel = false
+	defer func() { p.caseLabel = caseLabel }()
+	var args []Expr
+	if p.accept(KindRParen) {
+		return args
+	}
+	for {
+		args = append(args, p.parseExpression())
+		if !p.accept(KindComma) {
+			break
+		}
+	}
+	if !p.at(KindRParen) {
+		p.fail(open, "SYN102")
+	}
+	p.next()
+	return args
+}
+
+// startsOperand indica si el token puede empezar un operando
+func (p *Parser) startsOperand(token Token) bool {
+	return literalKinds[token.Type] || token.Type == KindIdentifier ||
+		token.Type == KindLParen || token.Type == KwThis
+}
+
+// isAssignable indica si la expresión puede recibir una asignación
+func isAssignable(x Expr) bool {
+	switch x := x.(type) {
+	case *Ident, *FieldAccess, *ArrayAccess:
+		return true
+	case *ParenExpr:
+		return isAssignable(x.X)
+	}
+	return false
+}
+
+// typeFromExpr convierte un nombre calificado usado como expresión en tipo
+func typeFromExpr(x Expr) *TypeNode {
+	return &TypeNode{Span: x.Range(), Name: qualifiedName(x)}
+}
+
+// setSpan actualiza la posición de un nodo recién construido
+func setSpan(x Expr, span Span) {
+	switch x := x.(type) {
+	case *MethodCall:
+		x.Span = span
+	case *FieldAccess:
+		x.Span = span
+	case *UnaryExpr:
+		x.Span = span
+	case *BinaryExpr:
+		x.Span = span
+	case *ArrayAccess:
+		x.Span = span
+	case *MethodRef:
+		x.Span = span
+	case *ClassLiteral:
+		x.Span = span
+	}
+}
@@ -0,0 +1,384 @@
+// analyzer/functional.go
+package analyzer
+
+import "strings"
+
+// functionalInterface forma de una interfaz funcional de la JDK: sus
+// parámetros de tipo y los tipos de los parámetros y del resultado de su
+// único método abstracto, que pueden nombrar los parámetros de tipo
+type functionalInterface struct {
+	typeParams []string
+	params     []string
+	result     string
+}
+
+// functionalInterfaces interfaces funcionales de java.util.function y las
+// de java.lang y java.util que se usan con lambdas
+var functionalInterfaces = map[string]functionalInterface{
+	"Runnable":   {nil, nil, "void"},
+	"Callable":   {[]string{"V"}, nil, "V"},
+	"Comparator": {[]string{"T"}, []string{"T", "T"}, "int"},
+
+	"Function":       {[]string{"T", "R"}, []string{"T"}, "R"},
+	"BiFunction":     {[]string{"T", "U", "R"}, []string{"T", "U"}, "R"},
+	"UnaryOperator":  {[]string{"T"}, []string{"T"}, "T"},
+	"BinaryOperator": {[]string{"T"}, []string{"T", "T"}, "T"},
+	"Consumer":       {[]string{"T"}, []string{"T"}, "void"},
+	"BiConsumer":     {[]string{"T", "U"}, []string{"T", "U"}, "void"},
+	"Supplier":       {[]string{"T"}, nil, "T"},
+	"Predicate":      {[]string{"T"}, []string{"T"}, "boolean"},
+	"BiPredicate":    {[]string{"T", "U"}, []string{"T", "U"}, "boolean"},
+
+	"IntFunction":      {[]string{"R"}, []string{"int"}, "R"},
+	"LongFunction":     {[]string{"R"}, []string{"long"}, "R"},
+	"DoubleFunction":   {[]string{"R"}, []string{"double"}, "R"},
+	"ToIntFunction":    {[]string{"T"}, []string{"T"}, "int"},
+	"ToLongFunction":   {[]string{"T"}, []string{"T"}, "long"},
+	"ToDoubleFunction": {[]string{"T"}, []string{"T"}, "double"},
+
+	"ToIntBiFunction":    {[]string{"T", "U"}, []string{"T", "U"}, "int"},
+	"ToLongBiFunction":   {[]string{"T", "U"}, []string{"T", "U"}, "long"},
+	"ToDoubleBiFunction": {[]string{"T", "U"}, []string{"T", "U"}, "double"},
+
+	"IntUnaryOperator":     {nil, []string{"int"}, "int"},
+	"LongUnaryOperator":    {nil, []string{"long"}, "long"},
+	"DoubleUnaryOperator":  {nil, []string{"double"}, "double"},
+	"IntBinaryOperator":    {nil, []string{"int", "int"}, "int"},
+	"LongBinaryOperator":   {nil, []string{"long", "long"}, "long"},
+	"DoubleBinaryOperator": {nil, []string{"double", "double"}, "double"},
+
+	"IntPredicate":    {nil, []string{"int"}, "boolean"},
+	"LongPredicate":   {nil, []string{"long"}, "boolean"},
+	"DoublePredicate": {nil, []string{"double"}, "boolean"},
+	"IntConsumer":     {nil, []string{"int"}, "void"},
+	"LongConsumer":    {nil, []string{"long"}, "void"},
+	"DoubleConsumer":  {nil, []string{"double"}, "void"},
+
+	"ObjIntConsumer":    {[]string{"T"}, []string{"T", "int"}, "void"},
+	"ObjLongConsumer":   {[]string{"T"}, []string{"T", "long"}, "void"},
+	"ObjDoubleConsumer": {[]string{"T"}, []string{"T", "double"}, "void"},
+
+	"IntSupplier":     {nil, nil, "int"},
+	"LongSupplier":    {nil, nil, "long"},
+	"DoubleSupplier":  {nil, nil, "double"},
+	"BooleanSupplier": {nil, nil, "boolean"},
+
+	"IntToLongFunction":    {nil, []string{"int"}, "long"},
+	"IntToDoubleFunction":  {nil, []string{"int"}, "double"},
+	"LongToIntFunction":    {nil, []string{"long"}, "int"},
+	"LongToDoubleFunction": {nil, []string{"long"}, "double"},
+	"DoubleToIntFunction":  {nil, []string{"double"}, "int"},
+	"DoubleToLongFunction": {nil, []string{"double"}, "long"},
+}
+
+// functionType tipo de función de una interfaz funcional concreta, como
+// "Function<String, Integer>": (String) -> Integer
+type functionType struct {
+	// target la interfaz tal y como se escribió, para los mensajes
+	target string
+	params []string
+	result string
+}
+
+// functionTypeOf devuelve el tipo de función de la interfaz target. ok es
+// false si target no puede recibir una lambda ni una referencia a método; fn
+// es nil si target es desconocido o su método no se conoce.
+func (c *checker) functionTypeOf(target string) (fn *functionType, ok bool) {
+	if target == typeUnknown {
+		return nil, true
+	}
+	if isPrimitiveType(target) || isKnownReferenceType(target) || isKnownGenericType(target) || target == "Object" {
+		return nil, false
+	}
+	if decl := c.lookupType(target); decl != nil {
+		if decl.Kind != "interface" {
+			return nil, false
+		}
+		abstract, complete := c.abstractMethods(decl)
+		if len(abstract) > 1 || len(abstract) == 0 && complete {
+			return nil, false
+		}
+		for _, method := range abstract {
+			fn := &functionType{target: target, result: typeName(method.ResultType, 0)}
+			for _, param := range method.Params {
+				fn.params = append(fn.params, typeName(param.Type, 0))
+			}
+			return fn, true
+		}
+		return nil, true
+	}
+	shape, known := functionalInterfaces[simpleTypeName(target)]
+	if !known {
+		return nil, true
+	}
+
+	// Cada parámetro de tipo toma el argumento genérico que le corresponde;
+	// si se usa el tipo sin argumentos quedan desconocidos
+	bindings := make(map[string]string)
+	if args := typeArguments(target); len(args) == len(shape.typeParams) {
+		for i, param := range shape.typeParams {
+			arg := args[i]
+			if bound, isSuper := strings.CutPrefix(arg, "? super "); isSuper {
+				arg = bound
+			}
+			bindings[param] = wildcardUpperBound(arg)
+		}
+	}
+	bind := func(t string) string {
+		if isPrimitiveType(t) || t == "void" {
+			return t
+		}
+		return bindings[t]
+	}
+	fn = &functionType{target: target, result: bind(shape.result)}
+	for _, param := range shape.params {
+		fn.params = append(fn.params, bind(param))
+	}
+	return fn, true
+}
+
+// isFunctionExpr indica si la expresión necesita un tipo destino: una
+// lambda o una referencia a método
+func isFunctionExpr(x Expr) bool {
+	switch unparen(x).(type) {
+	case *LambdaExpr, *MethodRef:
+		return true
+	}
+	return false
+}
+
+// typeOfExpected analiza una expresión que se asigna a un destino de tipo
+// expected. Las lambdas y referencias a método toman de él su tipo.
+func (c *checker) typeOfExpected(x Expr, expected string) string {
+	switch e := unparen(x).(type) {
+	case *LambdaExpr:
+		return c.checkLambda(e, expected)
+	case *MethodRef:
+		return c.checkMethodRef(e, expected)
+	}
+	return c.typeOf(x)
+}
+
+// checkLambda analiza una lambda cuyo tipo destino es target: declara sus
+// parámetros con los tipos de la interfaz funcional y comprueba su cuerpo
+// contra el resultado. Devuelve target, o typeUnknown si no es válido.
+func (c *checker) checkLambda(lambda *LambdaExpr, target string) string {
+	fn, ok := c.functionTypeOf(target)
+	if !ok {
+		c.errorf(lambda, "SEM113", target)
+		target = typeUnknown
+	}
+	if fn != nil && len(fn.params) != len(lambda.Params) {
+		c.errorf(lambda, "SEM114", countParams(len(lambda.Params)), fn.target, countParams(len(fn.params)))
+		fn = nil
+	}
+
+	outerFlow := c.flow
+	c.flow = flowContext{}
+	if fn != nil {
+		c.flow = flowContext{returnType: fn.result, lambda: fn.target}
+	}
+	defer func() { c.flow = outerFlow }()
+	c.withScope(func() {
+		for i, param := range lambda.Params {
+			typ := typeName(param.Type, 0)
+			if fn != nil && fn.params[i] != typeUnknown {
+				switch {
+				case typ == typeUnknown:
+					typ = fn.params[i]
+				case rawType(typ) != rawType(fn.params[i]):
+					c.errorf(param, "SEM115", param.Name, typ, fn.target, fn.params[i])
+				}
+			}
+			c.declare(param.Name, typ, param, nil)
+		}
+		switch body := lambda.Body.(type) {
+		case *Block:
+			c.checkStmts(body.Stmts)
+		case Expr:
+			typ := c.typeOf(body)
+			switch {
+			case fn == nil || fn.result == typeUnknown:
+			case fn.result == "void":
+				if !isStatementExpression(unparen(body)) {
+					c.errorf(body, "SEM116", fn.target)
+				}
+			case typ == "void" || !isAssignableType(fn.result, typ, constantValue(body)):
+				c.errorf(body, "SEM022", typ, fn.target, fn.result)
+			}
+		}
+	})
+	return target
+}
+
+// countParams escribe un número de parámetros: "1 parámetro", "2 parámetros"
+func countParams(n int) phrase {
+	if n == 1 {
+		return newPhrase("phrase.one_param")
+	}
+	return newPhrase("phrase.params", n)
+}
+
+// checkMethodRef analiza una referencia a método cuyo tipo destino es
+// target: comprueba que el método exista en los tipos declarados y en
+// String y que su resultado encaje con el de la interfaz funcional
+func (c *checker) checkMethodRef(ref *MethodRef, target string) string {
+	fn, ok := c.functionTypeOf(target)
+	if !ok {
+		c.errorf(ref, "SEM117", target)
+		target = typeUnknown
+	}
+
+	// Tipo que declara el método: el nombre de un tipo, this o una expresión
+	owner := typeUnknown
+	switch x := ref.X.(type) {
+	case nil:
+		owner = typeName(ref.Type, 0)
+	case *Ident:
+		if c.scope.lookup(x.Name) == nil && startsUpper(x.Name) {
+			owner = x.Name // nombre de un tipo: "String::valueOf"
+		} else {
+			owner = c.resolveName(x, true)
+		}
+	case *SuperExpr:
+	default:
+		owner = c.typeOf(x)
+	}
+
+	result := typeUnknown
+	decl := c.lookupType(owner)
+	switch {
+	case ref.Name == "new":
+		if decl != nil {
+			c.checkInstantiable(decl, ref)
+			result = decl.Name
+		}
+	case owner == "String":
+		if !c.isStringMethod(ref.Name) {
+			c.errorf(ref, "SEM041", ref.Name)
+		}
+		result = stringMethodTypes[ref.Name]
+	case decl != nil:
+		typ, found, known := c.findMethod(decl, ref.Name, 0)
+		if !found && known {
+			c.errorf(ref, "SEM118", ref.Name, decl.Name)
+		}
+		result = typ
+	}
+
+	if fn != nil && fn.result != "void" && fn.result != typeUnknown && result != typeUnknown &&
+		(result == "void" || !isAssignableType(fn.result, result, nil)) {
+		c.errorf(ref, "SEM119", ref.Name, result, fn.target, fn.result)
+	}
+	return target
+}
+
+// findMethod busca un método por su nombre en un tipo declarado y en sus
+// supertipos y devuelve su tipo de retorno. known es false si no se
+// encuentra pero algún supertipo no está declarado en el código o es un
+// enum, cuyos métodos implícitos no se conocen todos.
+func (c *checker) findMethod(decl *ClassDecl, name string, depth int) (result string, found, known bool) {
+	for _, member := range decl.Members {
+		if m, ok := member.(*MethodDecl); ok && !m.IsConstructor() && m.Name == name {
+			return typeName(m.ResultType, 0), true, true
+		}
+	}
+	for _, component := range decl.Components {
+		if component.Name == name {
+			return typeName(component.Type, 0), true, true
+		}
+	}
+	if isObjectMethod(name) {
+		return typeUnknown, true, true
+	}
+	if decl.Kind == "enum" || depth > 32 {
+		return typeUnknown, false, false
+	}
+	known = true
+	for _, super := range c.supertypes(decl) {
+		parent := c.lookupType(super.Name)
+		if parent == nil {
+			known = false
+			continue
+		}
+		result, found, superKnown := c.findMethod(parent, name, depth+1)
+		if found {
+			return result, true, true
+		}
+		known = known && superKnown
+	}
+	return typeUnknown, false, known
+}
+
+// isObjectMethod indica si name es un método público de Object
+func isObjectMethod(name string) bool {
+	switch name {
+	case "toString", "hashCode", "equals", "getClass", "notify", "notifyAll", "wait":
+		return true
+	}
+	return false
+}
+
+// argumentTarget devuelve el tipo del parámetro index de la llamada, para
+// dar tipo a la lambda o referencia a método que se pasa en él: el de un
+// método declarado en la unidad o el de los métodos de las colecciones que
+// reciben una interfaz funcional
+func (c *checker) argumentTarget(call *MethodCall, receiver string, index int) string {
+	if call.Target == nil {
+		// Solo si el método no está sobrecargado con el mismo número de parámetros
+		var found *MethodDecl
+		for _, method := range c.methodDecls[call.Name] {
+			if len(method.Params) == len(call.Args) && !method.Params[len(method.Params)-1].Varargs {
+				if found != nil {
+					return typeUnknown
+				}
+				found = method
+			}
+		}
+		if found == nil {
+			return typeUnknown
+		}
+		return typeName(found.Params[index].Type, 0)
+	}
+
+	args := typeArguments(receiver)
+	switch genericCollections[simpleTypeName(receiver)] {
+	case "Map":
+		if len(args) != 2 {
+			return typeUnknown
+		}
+		key, value := wildcardUpperBound(args[0]), wildcardUpperBound(args[1])
+		if key == typeUnknown || value == typeUnknown {
+			return typeUnknown
+		}
+		switch {
+		case call.Name == "forEach" && index == 0:
+			return "BiConsumer<" + key + ", " + value + ">"
+		case call.Name == "replaceAll" && index == 0:
+			return "BiFunction<" + key + ", " + value + ", " + value + ">"
+		case call.Name == "computeIfAbsent" && index == 1:
+			return "Function<" + key + ", " + value + ">"
+		}
+	case "List", "Set", "Queue", "Collection", "Iterable":
+		element := iterableElementType(receiver)
+		if element == typeUnknown || index != 0 {
+			return typeUnknown
+		}
+		switch call.Name {
+		case "forEach":
+			return "Consumer<" + element + ">"
+		case "removeIf":
+			return "Predicate<" + element + ">"
+		}
+		if genericCollections[simpleTypeName(receiver)] != "List" {
+			return typeUnknown
+		}
+		switch call.Name {
+		case "sort":
+			return "Comparator<" + element + ">"
+		case "replaceAll":
+			return "UnaryOperator<" + element + ">"
+		}
+	}
+	return typeUnknown
+}
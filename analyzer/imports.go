@@ -0,0 +1,241 @@
+// analyzer/imports.go
+package analyzer
+
+import "strings"
+
+// importScope nombres que la unidad importa, para resolver los nombres que
+// no son variables y avisar de los imports que no se usan
+type importScope struct {
+	// types imports de un tipo, por su nombre simple
+	types map[string]*ImportDecl
+	// members imports estáticos de un miembro, por su nombre
+	members map[string]*ImportDecl
+	// onDemand imports estáticos de todos los miembros de una clase
+	onDemand []*ImportDecl
+	// packages primer nombre de los paquetes declarados o importados, que
+	// pueden aparecer al principio de un nombre calificado
+	packages map[string]bool
+}
+
+// importText escribe un import tal y como aparece en el código
+func importText(decl *ImportDecl) string {
+	text := decl.Name
+	if decl.OnDemand {
+		text += ".*"
+	}
+	if decl.Static {
+		text = "static " + text
+	}
+	return text
+}
+
+// packageOf devuelve el nombre calificado sin su último componente:
+// "java.util" en "java.util.List"
+func packageOf(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// firstName devuelve el primer componente de un nombre calificado
+func firstName(name string) string {
+	return strings.SplitN(name, ".", 2)[0]
+}
+
+// checkImports registra la declaración package y los imports de la unidad y
+// comprueba que no se repitan ni choquen entre sí
+func (c *checker) checkImports(unit *CompilationUnit) {
+	c.imported = importScope{
+		types:    make(map[string]*ImportDecl),
+		members:  make(map[string]*ImportDecl),
+		packages: make(map[string]bool),
+	}
+	if unit.Package != nil {
+		c.imported.packages[firstName(unit.Package.Name)] = true
+		if strings.ToLower(unit.Package.Name) != unit.Package.Name {
+			c.warnf(unit.Package, "SEM084", unit.Package.Name)
+		}
+	}
+
+	seen := make(map[string]*ImportDecl)
+	for _, decl := range unit.Imports {
+		c.imported.packages[firstName(decl.Name)] = true
+		text := importText(decl)
+		if first, exists := seen[text]; exists {
+			c.warnf(decl, "SEM085", text).addLabel(first, "SEM085.first")
+			continue
+		}
+		seen[text] = decl
+
+		switch {
+		case decl.Static && decl.OnDemand:
+			c.imported.onDemand = append(c.imported.onDemand, decl)
+		case decl.Static:
+			c.imported.members[simpleTypeName(decl.Name)] = decl
+		case decl.OnDemand:
+			if decl.Name == "java.lang" {
+				c.warnf(decl, "SEM086", text)
+			}
+		case !strings.Contains(decl.Name, "."):
+			// Sin paquete; ya se reportó en el análisis sintáctico
+		default:
+			simple := simpleTypeName(decl.Name)
+			if other, exists := c.imported.types[simple]; exists {
+				c.errorf(decl, "SEM087", simple, other.Name).addLabel(other, "SEM087.previous")
+				continue
+			}
+			if c.lookupType(simple) != nil {
+				c.errorf(decl, "SEM088", decl.Name, simple)
+				continue
+			}
+			c.imported.types[simple] = decl
+			if packageOf(decl.Name) == "java.lang" {
+				c.warnf(decl, "SEM086", text)
+			}
+		}
+	}
+}
+
+// checkUnusedImports avisa de los imports de un tipo o de un miembro cuyo
+// nombre no aparece en el resto de la unidad ni en sus comentarios Javadoc.
+// Los imports ".*" no se comprueban porque no se conoce el contenido de
+// cada paquete.
+func (c *checker) checkUnusedImports(unit *CompilationUnit) {
+	used := make(map[string]bool)
+	Inspect(unit, func(node Node) bool {
+		switch n := node.(type) {
+		case *TypeNode:
+			used[firstName(n.Name)] = true
+		case *Annotation:
+			used[firstName(n.Name)] = true
+		case *Ident:
+			used[n.Name] = true
+		case *MethodCall:
+			if n.Target == nil {
+				used[n.Name] = true
+			}
+		}
+		return true
+	})
+	if unit.Module != nil {
+		// "uses Driver;" o "provides Driver with ..." también usan los imports
+		for _, directive := range unit.Module.Directives {
+			used[firstName(directive.Name)] = true
+			for _, target := range directive.Targets {
+				used[firstName(target)] = true
+			}
+		}
+	}
+	// "{@link List}" o "@see List" también usan el import de List
+	for _, doc := range c.docs {
+		for _, name := range doc.references() {
+			used[firstName(name)] = true
+		}
+	}
+
+	unused := make(map[*ImportDecl]bool)
+	for _, imports := range []map[string]*ImportDecl{c.imported.types, c.imported.members} {
+		for name, decl := range imports {
+			if !used[name] {
+				unused[decl] = true
+			}
+		}
+	}
+	// Se recorren en el orden del código para que los avisos salgan ordenados
+	for _, decl := range unit.Imports {
+		if unused[decl] {
+			c.warnf(decl, "SEM089", importText(decl))
+		}
+	}
+}
+
+// staticImportType devuelve el tipo de un nombre importado de forma estática.
+// ok es false si ningún import lo declara; los miembros de clases que no se
+// conocen se aceptan con tipo desconocido.
+func (c *checker) staticImportType(name string) (typ string, ok bool) {
+	if decl, exists := c.imported.members[name]; exists {
+		typ, _ := c.staticMemberType(packageOf(decl.Name), name)
+		return typ, true
+	}
+	for _, decl := range c.imported.onDemand {
+		if typ, known := c.staticMemberType(decl.Name, name); known || !c.isCatalogedClass(decl.Name) {
+			return typ, true
+		}
+	}
+	return typeUnknown, false
+}
+
+// staticMemberType devuelve el tipo del campo estático class.name, de una
+// clase declarada en el código o del catálogo de la biblioteca estándar
+func (c *checker) staticMemberType(class, name string) (string, bool) {
+	if c.lookupType(class) != nil {
+		return c.memberType(class, name)
+	}
+	typ, ok := libraryFieldTypes[simpleTypeName(class)+"."+name]
+	return typ, ok
+}
+
+// isCatalogedClass indica si se conocen todos los campos estáticos de la
+// clase: las del catálogo de la biblioteca y las declaradas en el código
+func (c *checker) isCatalogedClass(class string) bool {
+	if c.lookupType(class) != nil {
+		return true
+	}
+	prefix := simpleTypeName(class) + "."
+	for member := range libraryFieldTypes {
+		if strings.HasPrefix(member, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// staticImportCall devuelve el tipo de retorno de un método importado de
+// forma estática, como max en "import static java.lang.Math.max;"
+func (c *checker) staticImportCall(name string, args []string) string {
+	if decl, ok := c.imported.members[name]; ok {
+		return libraryMethodType(simpleTypeName(packageOf(decl.Name)), name, args)
+	}
+	for _, decl := range c.imported.onDemand {
+		if typ := libraryMethodType(simpleTypeName(decl.Name), name, args); typ != typeUnknown {
+			return typ
+		}
+	}
+	return typeUnknown
+}
+
+// checkModule comprueba las directivas de module-info.java
+func (c *checker) checkModule(unit *CompilationUnit) {
+	module := unit.Module
+	if len(unit.Types) > 0 {
+		c.errorf(unit.Types[0], "SEM090", unit.Types[0].Name)
+	}
+
+	seen := make(map[string]bool)
+	for _, directive := range module.Directives {
+		key := directive.Kind + " " + directive.Name
+		if seen[key] {
+			c.errorf(directive, "SEM091", directive.Name, directive.Kind, module.Name)
+		}
+		seen[key] = true
+
+		switch directive.Kind {
+		case "requires":
+			if directive.Name == module.Name {
+				c.errorf(directive, "SEM092", module.Name)
+			}
+		case "opens":
+			if module.Open {
+				c.errorf(directive, "SEM093")
+			}
+		}
+		targets := make(map[string]bool)
+		for _, target := range directive.Targets {
+			if targets[target] {
+				c.errorf(directive, "SEM094", target, directive.Kind, directive.Name)
+			}
+			targets[target] = true
+		}
+	}
+}
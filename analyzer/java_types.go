@@ -0,0 +1,406 @@
+// analyzer/java_types.go
+package analyzer
+
+import "strings"
+
+// Los tipos se representan por su nombre en Java ("int", "String",
+// "int[]"). La cadena vacía es un tipo desconocido, compatible con todo, para
+// no reportar errores en cascada sobre expresiones que no se pudieron tipar.
+const (
+	typeUnknown = ""
+	typeNull    = "null"
+)
+
+// numericRank orden de ampliación de los tipos numéricos primitivos
+var numericRank = map[string]int{
+	"byte": 1, "short": 2, "char": 2, "int": 3, "long": 4, "float": 5, "double": 6,
+}
+
+// boxedTypes clase envoltorio de cada tipo primitivo
+var boxedTypes = map[string]string{
+	"boolean": "Boolean", "byte": "Byte", "short": "Short", "char": "Character",
+	"int": "Integer", "long": "Long", "float": "Float", "double": "Double",
+}
+
+// unboxedTypes tipo primitivo de cada clase envoltorio
+var unboxedTypes = func() map[string]string {
+	types := make(map[string]string, len(boxedTypes))
+	for primitive, boxed := range boxedTypes {
+		types[boxed] = primitive
+	}
+	return types
+}()
+
+func isPrimitiveType(t string) bool {
+	_, ok := boxedTypes[t]
+	return ok
+}
+
+// unbox devuelve el tipo primitivo de una clase envoltorio, o t sin cambios
+func unbox(t string) string {
+	if primitive, ok := unboxedTypes[t]; ok {
+		return primitive
+	}
+	return t
+}
+
+func isNumericType(t string) bool {
+	_, ok := numericRank[unbox(t)]
+	return ok
+}
+
+func isIntegralType(t string) bool {
+	switch unbox(t) {
+	case "byte", "short", "char", "int", "long":
+		return true
+	}
+	return false
+}
+
+func isBooleanType(t string) bool {
+	return unbox(t) == "boolean"
+}
+
+func isArrayType(t string) bool {
+	return strings.HasSuffix(t, "[]")
+}
+
+// elementType devuelve el tipo de los elementos de un array
+func elementType(t string) string {
+	if !isArrayType(t) {
+		return typeUnknown
+	}
+	return t[:len(t)-2]
+}
+
+// typeName devuelve el nombre de un tipo del árbol, con sus argumentos
+// genéricos y sus dimensiones: "Map<String, List<Integer>>[]". Los
+// parámetros de tipo como T se tratan como tipos desconocidos.
+func typeName(node *TypeNode, extraDims int) string {
+	if node == nil {
+		return typeUnknown
+	}
+	if node.Name == "var" {
+		return typeUnknown // se infiere del inicializador
+	}
+	if node.TypeVar && node.Dims+extraDims == 0 {
+		return typeUnknown
+	}
+	return typeArgName(node) + strings.Repeat("[]", node.Dims+extraDims)
+}
+
+// typeArgName escribe un tipo sin sus dimensiones, incluidos los comodines
+// que aparecen como argumentos genéricos. Un parámetro de tipo como argumento
+// se escribe como el comodín "?": su valor depende de la llamada, como en
+// "List<T> wrap(T t)", y no se compara.
+func typeArgName(node *TypeNode) string {
+	if node.Name == "?" {
+		switch {
+		case node.Bound == nil:
+			return "?"
+		case node.Super:
+			return "? super " + typeName(node.Bound, 0)
+		}
+		return "? extends " + typeName(node.Bound, 0)
+	}
+	if len(node.Args) == 0 {
+		return node.Name
+	}
+	args := make([]string, len(node.Args))
+	for i, arg := range node.Args {
+		if arg.TypeVar {
+			args[i] = "?"
+			continue
+		}
+		args[i] = typeArgName(arg) + strings.Repeat("[]", arg.Dims)
+	}
+	return node.Name + "<" + strings.Join(args, ", ") + ">"
+}
+
+// rawType quita los argumentos genéricos de un tipo: "List<String>" es "List"
+func rawType(t string) string {
+	if i := strings.IndexByte(t, '<'); i >= 0 {
+		return t[:i]
+	}
+	return t
+}
+
+// typeArguments devuelve los argumentos genéricos de primer nivel de un
+// tipo: "Map<String, List<Integer>>" da ["String", "List<Integer>"]
+func typeArguments(t string) []string {
+	open := strings.IndexByte(t, '<')
+	if open < 0 || !strings.HasSuffix(t, ">") {
+		return nil
+	}
+	var args []string
+	depth, start := 0, open+1
+	for i := open + 1; i < len(t)-1; i++ {
+		switch t[i] {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(t[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(args, strings.TrimSpace(t[start:len(t)-1]))
+}
+
+// genericCollections interfaz de la JDK de cada colección genérica conocida,
+// cuyos parámetros de tipo se corresponden uno a uno con los de la interfaz
+var genericCollections = map[string]string{
+	"Collection": "Collection", "Iterable": "Iterable",
+	"List": "List", "ArrayList": "List", "LinkedList": "List", "Vector": "List", "Stack": "List",
+	"Set": "Set", "HashSet": "Set", "LinkedHashSet": "Set", "TreeSet": "Set",
+	"Queue": "Queue", "Deque": "Queue", "ArrayDeque": "Queue", "PriorityQueue": "Queue",
+	"Map": "Map", "HashMap": "Map", "LinkedHashMap": "Map", "TreeMap": "Map",
+	"Optional": "Optional",
+}
+
+// iterableElementType devuelve el tipo de los elementos de una colección
+// genérica conocida, o typeUnknown si no se puede deducir
+func iterableElementType(t string) string {
+	family := genericCollections[simpleTypeName(t)]
+	if family == "" || family == "Map" || family == "Optional" {
+		return typeUnknown
+	}
+	args := typeArguments(t)
+	if len(args) != 1 {
+		return typeUnknown
+	}
+	return wildcardUpperBound(args[0])
+}
+
+// wildcardUpperBound devuelve el tipo que se puede leer de un argumento
+// genérico: el propio tipo, el límite de "? extends X" o desconocido
+func wildcardUpperBound(arg string) string {
+	if bound, ok := strings.CutPrefix(arg, "? extends "); ok {
+		return bound
+	}
+	if strings.HasPrefix(arg, "?") {
+		return typeUnknown
+	}
+	return arg
+}
+
+// genericArgsCompatible indica si los argumentos genéricos de source encajan
+// en los de target. Los genéricos son invariantes: List<Object> no admite un
+// ArrayList<String>. Solo se comprueban las colecciones de la JDK, en las que
+// la correspondencia entre parámetros es conocida, y los argumentos sin
+// comodines, tampoco anidados como en List<List<?>>.
+func genericArgsCompatible(target, source string) bool {
+	targetFamily := genericCollections[simpleTypeName(target)]
+	sourceFamily := genericCollections[simpleTypeName(source)]
+	if targetFamily == "" || targetFamily != sourceFamily {
+		return true
+	}
+	targetArgs, sourceArgs := typeArguments(target), typeArguments(source)
+	if len(targetArgs) == 0 || len(targetArgs) != len(sourceArgs) {
+		return true // tipo raw o diamante
+	}
+	for i, arg := range targetArgs {
+		if strings.Contains(arg, "?") || strings.Contains(sourceArgs[i], "?") {
+			continue
+		}
+		if arg != sourceArgs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// unaryPromotion aplica la promoción numérica unaria (JLS §5.6)
+func unaryPromotion(t string) string {
+	switch t = unbox(t); t {
+	case "byte", "short", "char":
+		return "int"
+	}
+	return t
+}
+
+// binaryPromotion aplica la promoción numérica binaria (JLS §5.6)
+func binaryPromotion(a, b string) string {
+	a, b = unbox(a), unbox(b)
+	for _, t := range []string{"double", "float", "long"} {
+		if a == t || b == t {
+			return t
+		}
+	}
+	return "int"
+}
+
+// isWidening indica si el primitivo from se amplía a to sin conversión explícita
+func isWidening(from, to string) bool {
+	if from == to {
+		return true
+	}
+	fromRank, ok1 := numericRank[from]
+	toRank, ok2 := numericRank[to]
+	if !ok1 || !ok2 || fromRank >= toRank {
+		return false
+	}
+	// char no se amplía a short ni short/byte a char
+	if from == "char" {
+		return toRank >= numericRank["int"]
+	}
+	return to != "char"
+}
+
+// constantFits indica si una constante entera cabe en el tipo indicado,
+// lo que permite asignar "byte b = 10;" o "char c = 65;"
+func constantFits(value int64, t string) bool {
+	switch unbox(t) {
+	case "byte":
+		return value >= -128 && value <= 127
+	case "short":
+		return value >= -32768 && value <= 32767
+	case "char":
+		return value >= 0 && value <= 0xFFFF
+	}
+	return false
+}
+
+// isAssignableType indica si un valor de tipo source puede asignarse a una
+// variable de tipo target (JLS §5.2). constant es el valor del inicializador
+// si es una expresión constante entera.
+func isAssignableType(target, source string, constant *int64) bool {
+	if target == typeUnknown || source == typeUnknown || target == source {
+		return true
+	}
+	if source == typeNull {
+		return !isPrimitiveType(target)
+	}
+
+	if isPrimitiveType(target) {
+		source = unbox(source)
+		if !isPrimitiveType(source) {
+			return false
+		}
+		if isWidening(source, target) {
+			return true
+		}
+		return constant != nil && numericRank[source] <= numericRank["int"] && constantFits(*constant, target)
+	}
+
+	if isPrimitiveType(source) {
+		// Conversión a la clase envoltorio, o a Object/Number
+		switch target {
+		case boxedTypes[source], "Object", "Serializable", "Comparable":
+			return true
+		case "Number":
+			return isNumericType(source) && source != "char"
+		}
+		return constant != nil && unboxedTypes[target] != "" && constantFits(*constant, target) &&
+			numericRank[source] <= numericRank["int"]
+	}
+
+	if target == "Object" {
+		return true
+	}
+	if isArrayType(target) || isArrayType(source) {
+		return isAssignableArray(target, source)
+	}
+	if !genericArgsCompatible(target, source) {
+		return false
+	}
+	// Entre referencias solo se rechazan los tipos conocidos que no se
+	// relacionan entre sí: String y los envoltorios
+	if isKnownReferenceType(target) && isKnownReferenceType(source) {
+		return false
+	}
+	return true
+}
+
+// isAssignableArray aplica la covarianza de los arrays (JLS §4.10.3): un
+// array de referencias se asigna a otro si sus elementos se asignan, y
+// cualquier array se asigna a Object, Cloneable y Serializable
+func isAssignableArray(target, source string) bool {
+	switch {
+	case !isArrayType(source):
+		// Solo con una conversión explícita, salvo que el origen sea conocido
+		return !isKnownReferenceType(source)
+	case !isArrayType(target):
+		switch simpleTypeName(target) {
+		case "Object", "Cloneable", "Serializable":
+			return true
+		}
+		return false
+	}
+	targetElem, sourceElem := elementType(target), elementType(source)
+	if isPrimitiveType(targetElem) || isPrimitiveType(sourceElem) {
+		return targetElem == sourceElem
+	}
+	return isAssignableType(targetElem, sourceElem, nil)
+}
+
+// isKnownReferenceType indica si el tipo es una clase final conocida, cuya
+// compatibilidad con otra clase distinta puede decidirse sin su jerarquía
+func isKnownReferenceType(t string) bool {
+	return t == "String" || unboxedTypes[t] != "" || isArrayType(t)
+}
+
+// stringMethodTypes tipo de retorno de los métodos de String
+var stringMethodTypes = map[string]string{
+	"length": "int", "charAt": "char", "substring": "String", "indexOf": "int",
+	"lastIndexOf": "int", "equals": "boolean", "equalsIgnoreCase": "boolean",
+	"compareTo": "int", "compareToIgnoreCase": "int", "startsWith": "boolean",
+	"endsWith": "boolean", "contains": "boolean", "toUpperCase": "String",
+	"toLowerCase": "String", "trim": "String", "replace": "String",
+	"replaceAll": "String", "split": "String[]", "valueOf": "String",
+	"toString": "String", "isEmpty": "boolean", "isBlank": "boolean",
+	"strip": "String", "repeat": "String", "concat": "String",
+	"hashCode": "int", "toCharArray": "char[]",
+}
+
+// libraryMethodTypes tipo de retorno de los métodos estáticos de la
+// biblioteca estándar más usados en los ejercicios
+var libraryMethodTypes = map[string]string{
+	"Integer.parseInt": "int", "Integer.valueOf": "Integer", "Integer.toString": "String",
+	"Long.parseLong": "long", "Double.parseDouble": "double", "Float.parseFloat": "float",
+	"Boolean.parseBoolean": "boolean", "String.valueOf": "String", "String.format": "String",
+	"String.join": "String", "Character.isDigit": "boolean", "Character.isLetter": "boolean",
+	"Character.toUpperCase": "char", "Character.toLowerCase": "char",
+	"Math.sqrt": "double", "Math.pow": "double", "Math.random": "double",
+	"Math.floor": "double", "Math.ceil": "double", "Math.round": "long",
+	"System.currentTimeMillis": "long", "System.nanoTime": "long",
+}
+
+// libraryFieldTypes tipo de los campos estáticos de la biblioteca estándar
+// más usados en los ejercicios
+var libraryFieldTypes = map[string]string{
+	"System.out": "PrintStream", "System.err": "PrintStream", "System.in": "InputStream",
+	"Math.PI": "double", "Math.E": "double",
+	"Integer.MAX_VALUE": "int", "Integer.MIN_VALUE": "int",
+	"Long.MAX_VALUE": "long", "Long.MIN_VALUE": "long",
+	"Short.MAX_VALUE": "short", "Short.MIN_VALUE": "short",
+	"Byte.MAX_VALUE": "byte", "Byte.MIN_VALUE": "byte",
+	"Character.MAX_VALUE": "char", "Character.MIN_VALUE": "char",
+	"Double.MAX_VALUE": "double", "Double.MIN_VALUE": "double", "Double.NaN": "double",
+	"Double.POSITIVE_INFINITY": "double", "Double.NEGATIVE_INFINITY": "double",
+	"Float.MAX_VALUE": "float", "Float.MIN_VALUE": "float",
+	"Boolean.TRUE": "Boolean", "Boolean.FALSE": "Boolean",
+}
+
+// libraryMethodType devuelve el tipo de retorno de Clase.metodo(args). Math.abs,
+// Math.max y Math.min devuelven el tipo promovido de sus argumentos.
+func libraryMethodType(class, method string, args []string) string {
+	if class == "Math" && (method == "abs" || method == "max" || method == "min") {
+		result := typeUnknown
+		for _, arg := range args {
+			if !isNumericType(arg) {
+				return typeUnknown
+			}
+			if result == typeUnknown {
+				result = unaryPromotion(arg)
+			} else {
+				result = binaryPromotion(result, arg)
+			}
+		}
+		return result
+	}
+	return libraryMethodTypes[class+"."+method]
+}
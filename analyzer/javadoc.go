@@ -0,0 +1,123 @@
+// analyzer/javadoc.go
+package analyzer
+
+import (
+	"strings"
+)
+
+// DocComment contenido estructurado de un comentario Javadoc
+type DocComment struct {
+	Summary string
+	Tags    []DocTag `json:",omitempty"`
+}
+
+// DocTag etiqueta de bloque de un Javadoc (@param, @return, @throws...)
+type DocTag struct {
+	Name string
+	Arg  string `json:",omitempty"`
+	Text string
+}
+
+// tagsWithArgument etiquetas cuyo primer término es un nombre (parámetro o excepción)
+var tagsWithArgument = map[string]bool{
+	"param":       true,
+	"throws":      true,
+	"exception":   true,
+	"serialField": true,
+}
+
+// ParseJavadoc extrae la descripción y las etiquetas de bloque de un comentario "/** ... */"
+func ParseJavadoc(text string) *DocComment {
+	body := strings.TrimPrefix(text, "/**")
+	body = strings.TrimSuffix(body, "*/")
+
+	doc := &DocComment{}
+	var summary []string
+	var current *DocTag
+
+	for _, rawLine := range strings.Split(body, "\n") {
+		line := strings.TrimSpace(strings.TrimSuffix(rawLine, "\r"))
+		line = strings.TrimSpace(strings.TrimLeft(line, "*"))
+
+		if strings.HasPrefix(line, "@") {
+			name, rest, _ := strings.Cut(line[1:], " ")
+			tag := DocTag{Name: name, Text: strings.TrimSpace(rest)}
+			if tagsWithArgument[name] {
+				tag.Arg, tag.Text, _ = strings.Cut(tag.Text, " ")
+				tag.Text = strings.TrimSpace(tag.Text)
+			}
+			doc.Tags = append(doc.Tags, tag)
+			current = &doc.Tags[len(doc.Tags)-1]
+			continue
+		}
+
+		if line == "" {
+			continue
+		}
+		if current != nil {
+			// Continuación de la etiqueta anterior
+			current.Text = strings.TrimSpace(current.Text + " " + line)
+		} else {
+			summary = append(summary, line)
+		}
+	}
+
+	doc.Summary = strings.Join(summary, " ")
+	return doc
+}
+
+// inlineReferenceTags etiquetas en línea cuyo primer término es una
+// referencia a un tipo o a un miembro: "{@link List#size()}"
+var inlineReferenceTags = []string{"{@link ", "{@linkplain ", "{@value "}
+
+// references devuelve los nombres de tipo que cita el comentario en sus
+// etiquetas {@link}, @see y @throws, sin el miembro: "List" en "List#size()"
+func (d *DocComment) references() []string {
+	var names []string
+	add := func(ref string) {
+		ref, _, _ = strings.Cut(ref, "#")
+		ref, _, _ = strings.Cut(ref, "(")
+		if ref != "" && isIdentifierStart([]rune(ref)[0]) {
+			names = append(names, ref)
+		}
+	}
+
+	texts := []string{d.Summary}
+	for _, tag := range d.Tags {
+		texts = append(texts, tag.Text)
+		switch tag.Name {
+		case "see":
+			ref, _, _ := strings.Cut(tag.Text, " ")
+			add(ref)
+		case "throws", "exception":
+			add(tag.Arg)
+		}
+	}
+	for _, text := range texts {
+		for _, prefix := range inlineReferenceTags {
+			for rest := text; ; {
+				_, after, found := strings.Cut(rest, prefix)
+				if !found {
+					break
+				}
+				rest = strings.TrimSpace(after)
+				ref := strings.FieldsFunc(rest, func(r rune) bool { return r == ' ' || r == '}' })
+				if len(ref) > 0 {
+					add(ref[0])
+				}
+			}
+		}
+	}
+	return names
+}
+
+// javadocs devuelve los comentarios Javadoc de los tokens, en orden
+func javadocs(tokens []Token) []*DocComment {
+	var docs []*DocComment
+	for _, token := range tokens {
+		if token.Doc != nil {
+			docs = append(docs, token.Doc)
+		}
+	}
+	return docs
+}
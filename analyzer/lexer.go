@@ -1,264 +1,105 @@
 package analyzer
 
 import (
+	"strings"
 	"unicode"
 )
 
+// LexerOptions configura las optimizaciones de memoria del lexer. Ninguna
+// opción cambia los tokens producidos, solo cómo se reserva su memoria.
+type LexerOptions struct {
+	// Strings, si no es nil, se usa para internar los valores de los tokens
+	Strings *StringLibrary
+	// Preallocate reserva de antemano la capacidad estimada del resultado
+	Preallocate bool
+	// BufferSize número de caracteres que el Scanner lee de cada vez
+	BufferSize int
+}
+
+// Lex realiza el análisis léxico sin optimizaciones de memoria
 func Lex(input string) []Token {
-	var tokens []Token
-	runes := []rune(input)
-	i := 0
-	line := 1
-	col := 1
+	return LexWithOptions(input, LexerOptions{})
+}
 
-	for i < len(runes) {
-		c := runes[i]
+// LexWithOptions realiza el análisis léxico con las opciones indicadas
+func LexWithOptions(input string, opts LexerOptions) []Token {
+	var tokens []Token
+	if opts.Preallocate {
+		tokens = make([]Token, 0, len(input)/4) // Estimación heurística
+	}
+	if opts.BufferSize <= 0 {
+		// Todo el código ya está en memoria: leerlo de una vez
+		opts.BufferSize = len(input)
+	}
+	for token := range NewScannerWithOptions(strings.NewReader(input), opts).Tokens() {
+		tokens = append(tokens, token)
+	}
+	return tokens
+}
 
-		if c == '\n' {
-			line++
-			col = 1
-			i++
-			continue
-		}
+// lexToken reconoce el token que empieza en runes[*i], que no es un espacio
+// en blanco, y avanza la posición
+func lexToken(runes []rune, i *int, line *int, col *int) *Token {
+	c := runes[*i]
 
-		if unicode.IsSpace(c) {
-			col++
-			i++
-			continue
+	switch {
+	case isIdentifierStart(c):
+		start := *i
+		startCol := *col
+		for *i < len(runes) && isIdentifierPart(runes[*i]) {
+			*i++
+			*col++
 		}
+		word := string(runes[start:*i])
+		return &Token{Type: identifierKind(word), Value: word, Line: *line, Col: startCol}
+	case startsNumber(runes, *i):
+		return lexNumber(runes, i, col, *line)
+	case c == '"' || c == '\'':
+		return lexQuoted(runes, i, line, col)
+	}
 
-		if unicode.IsLetter(c) || c == '_' {
-			start := i
-			startCol := col
-			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
-				i++
-				col++
-			}
-			word := string(runes[start:i])
-			tokenType := "identifier"
-			if keywords[word] {
-				tokenType = "keyword"
-			}
-			tokens = append(tokens, Token{Type: tokenType, Value: word, Line: line, Col: startCol})
-			continue
-		}
+	// Comentarios de línea, de bloque y Javadoc
+	if token := lexComment(runes, i, line, col); token != nil {
+		return token
+	}
+	// Operadores y separadores por coincidencia máxima
+	if token := lexOperator(runes, i, col, *line); token != nil {
+		return token
+	}
 
-		if unicode.IsDigit(c) {
-			start := i
-			startCol := col
-			for i < len(runes) && unicode.IsDigit(runes[i]) {
-				i++
-				col++
-			}
-			tokens = append(tokens, Token{Type: "number", Value: string(runes[start:i]), Line: line, Col: startCol})
-			continue
-		}
+	diagnostic := newDiagnostic("LEX001", SeverityError, Span{}, string(c))
+	token := &Token{Type: KindUnknown, Value: string(c), Line: *line, Col: *col, Diagnostic: &diagnostic}
+	*i++
+	*col++
+	return token
+}
 
-		switch c {
-		case '+':
-			if i+1 < len(runes) && runes[i+1] == '+' {
-				// Verificar si hay un tercer + (c+++)
-				if i+2 < len(runes) && runes[i+2] == '+' {
-					// Tokenizar como ++ y luego +
-					tokens = append(tokens, Token{Type: "operator", Value: "++", Line: line, Col: col})
-					tokens = append(tokens, Token{Type: "operator", Value: "+", Line: line, Col: col + 2})
-					i += 3
-					col += 3
-				} else {
-					tokens = append(tokens, Token{Type: "operator", Value: "++", Line: line, Col: col})
-					i += 2
-					col += 2
-				}
-			} else if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "+=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "+", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '-':
-			if i+1 < len(runes) && runes[i+1] == '-' {
-				// Verificar si hay un tercer - (c---)
-				if i+2 < len(runes) && runes[i+2] == '-' {
-					// Tokenizar como -- y luego -
-					tokens = append(tokens, Token{Type: "operator", Value: "--", Line: line, Col: col})
-					tokens = append(tokens, Token{Type: "operator", Value: "-", Line: line, Col: col + 2})
-					i += 3
-					col += 3
-				} else {
-					tokens = append(tokens, Token{Type: "operator", Value: "--", Line: line, Col: col})
-					i += 2
-					col += 2
-				}
-			} else if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "-=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "-", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '*':
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "*=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "*", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '/':
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "/=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "/", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '<':
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "<=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "<", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '>':
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: ">=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: ">", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '=':
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "==", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "=", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '!':
-			if i+1 < len(runes) && runes[i+1] == '=' {
-				tokens = append(tokens, Token{Type: "operator", Value: "!=", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "!", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '&':
-			if i+1 < len(runes) && runes[i+1] == '&' {
-				tokens = append(tokens, Token{Type: "operator", Value: "&&", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "&", Line: line, Col: col})
-				i++
-				col++
-			}
-		case '|':
-			if i+1 < len(runes) && runes[i+1] == '|' {
-				tokens = append(tokens, Token{Type: "operator", Value: "||", Line: line, Col: col})
-				i += 2
-				col += 2
-			} else {
-				tokens = append(tokens, Token{Type: "operator", Value: "|", Line: line, Col: col})
-				i++
-				col++
-			}
-		case ';':
-			tokens = append(tokens, Token{Type: "semicolon", Value: ";", Line: line, Col: col})
-			i++
-			col++
-		case '(':
-			tokens = append(tokens, Token{Type: "lparen", Value: "(", Line: line, Col: col})
-			i++
-			col++
-		case ')':
-			tokens = append(tokens, Token{Type: "rparen", Value: ")", Line: line, Col: col})
-			i++
-			col++
-		case '{':
-			tokens = append(tokens, Token{Type: "lbrace", Value: "{", Line: line, Col: col})
-			i++
-			col++
-		case '}':
-			tokens = append(tokens, Token{Type: "rbrace", Value: "}", Line: line, Col: col})
-			i++
-			col++
-		case ',':
-			tokens = append(tokens, Token{Type: "comma", Value: ",", Line: line, Col: col})
-			i++
-			col++
-		case '.':
-			tokens = append(tokens, Token{Type: "dot", Value: ".", Line: line, Col: col})
-			i++
-			col++
-		case '"':
-			start := i + 1
-			startCol := col
-			i++
-			col++
-			for i < len(runes) && runes[i] != '"' {
-				if runes[i] == '\n' {
-					line++
-					col = 1
-				} else {
-					col++
-				}
-				i++
-			}
-			if i >= len(runes) {
-				tokens = append(tokens, Token{Type: "error", Value: "String sin cerrar", Line: line, Col: startCol})
-			} else {
-				value := string(runes[start:i])
-				tokens = append(tokens, Token{Type: "string", Value: value, Line: line, Col: startCol})
-				i++ // cerrar comillas
-				col++
-			}
-		case '\'':
-			start := i + 1
-			startCol := col
-			i++
-			col++
-			if i < len(runes) && runes[i] != '\'' {
-				if i+1 < len(runes) && runes[i+1] == '\'' {
-					// Char literal válido
-					value := string(runes[start : i+1])
-					tokens = append(tokens, Token{Type: "char", Value: value, Line: line, Col: startCol})
-					i += 2 // saltar char y comilla de cierre
-					col += 2
-				} else {
-					tokens = append(tokens, Token{Type: "error", Value: "Char literal inválido", Line: line, Col: startCol})
-					i++
-					col++
-				}
-			} else {
-				tokens = append(tokens, Token{Type: "error", Value: "Char literal vacío", Line: line, Col: startCol})
-				i++
-				col++
-			}
-		default:
-			tokens = append(tokens, Token{Type: "unknown", Value: string(c), Line: line, Col: col})
-			i++
-			col++
-		}
+// utf16Len devuelve las unidades UTF-16 que ocupa r
+func utf16Len(r rune) int {
+	if r >= 0x10000 && r <= unicode.MaxRune {
+		return 2
 	}
+	return 1
+}
 
-	return tokens
+// isWhitespace indica si el carácter es un espacio en blanco de Java (JLS
+// §3.6): espacio, tabulador, avance de página o retorno de carro
+func isWhitespace(c rune) bool {
+	return c == ' ' || c == '\t' || c == '\f' || c == '\r'
+}
+
+// isIdentifierStart equivale a Character.isJavaIdentifierStart
+func isIdentifierStart(c rune) bool {
+	return unicode.IsLetter(c) || unicode.Is(unicode.Nl, c) ||
+		unicode.Is(unicode.Sc, c) || unicode.Is(unicode.Pc, c)
+}
+
+// isIdentifierPart equivale a Character.isJavaIdentifierPart
+func isIdentifierPart(c rune) bool {
+	return isIdentifierStart(c) || unicode.IsDigit(c) ||
+		unicode.Is(unicode.Mn, c) || unicode.Is(unicode.Mc, c)
+}
+
+func isDigit(c rune) bool {
+	return c >= '0' && c <= '9'
 }
@@ -0,0 +1,153 @@
+// analyzer/lexer_test.go
+package analyzer
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestLexersAgree comprueba que Lex, LexOptimized y el Scanner producen los
+// mismos tokens, también cuando el Scanner lee con buffers pequeños que
+// parten los tokens
+func TestLexersAgree(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"vacío", ""},
+		{"clase", "public class Main {\n  public static void main(String[] args) {\n    System.out.println(\"Hola\");\n  }\n}\n"},
+		{"operadores", "a >>>= b >> c >= d != e && f || !g ? h : i -> j :: k ++ --"},
+		{"números", "0 42 0x1F 0b1010 017 1_000 3.14 1e10 2.5f 7L .5 1.e3 0x1.8p1"},
+		{"números mal formados", "0x 1e 08 1__ 0b2 3.14.15"},
+		{"strings y chars", `"a\tb\"c" 'x' '\n' 'A' "é"`},
+		{"strings sin cerrar", "\"abc\nint x = 'y"},
+		{"chars inválidos", "'' 'ab' '\\q'"},
+		{"bloque de texto", "String s = \"\"\"\n    Hola\n      mundo\\n\n    \"\"\";"},
+		{"bloque de texto sin cerrar", "String s = \"\"\"\n  abc"},
+		{"comentarios", "// línea\nint /* bloque */ x; /** doc */"},
+		{"comentario sin cerrar", "int x; /* sin cerrar"},
+		{"caracteres no válidos", "int # x @ ` y \\ z"},
+		{"unicode", "String ñandú = \"😀\"; int π = 3; // ü\n char c = 'é';"},
+		{"saltos de línea CRLF", "int x;\r\nint y;\r\n\r\n"},
+		{"anotaciones y genéricos", "@Override public <T extends Comparable<T>> List<Map<String, T>> f() {}"},
+	}
+	optimized := NewOptimizedLexer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := Lex(tt.input)
+			if got := optimized.LexOptimized(tt.input); !sameTokens(got, want) {
+				t.Errorf("LexOptimized = %+v, Lex = %+v", got, want)
+			}
+			for _, size := range []int{1, 2, 3, 7, 16, 4096} {
+				var got []Token
+				scanner := NewScannerWithOptions(strings.NewReader(tt.input), LexerOptions{BufferSize: size})
+				for token := range scanner.Tokens() {
+					got = append(got, token)
+				}
+				if !sameTokens(got, want) {
+					t.Errorf("Scanner con buffer %d = %+v, Lex = %+v", size, got, want)
+				}
+			}
+		})
+	}
+}
+
+// sameTokens compara dos listas de tokens sin distinguir la vacía de nil
+func sameTokens(a, b []Token) bool {
+	return len(a) == 0 && len(b) == 0 || reflect.DeepEqual(a, b)
+}
+
+// TestScannerPeekUnread comprueba el orden en que Next entrega los tokens
+// tras Peek y Unread. Cada paso es "next", "peek" o "unread", que devuelve
+// el último token leído con next; want son los valores que leen next y peek.
+func TestScannerPeekUnread(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []string
+		want  []string
+	}{
+		{"peek no consume", []string{"peek", "peek", "next", "next"}, []string{"a", "a", "a", "+"}},
+		{"unread devuelve el token", []string{"next", "unread", "next", "next"}, []string{"a", "a", "+"}},
+		{"unread en orden inverso", []string{"next", "next", "unread", "peek", "next", "next"}, []string{"a", "+", "+", "+", "b"}},
+		{"peek tras unread", []string{"next", "next", "next", "unread", "peek", "next", "next"}, []string{"a", "+", "b", "b", "b", ";"}},
+		{"final del código", []string{"next", "next", "next", "next", "peek", "next"}, []string{"a", "+", "b", ";", "", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScannerWithOptions(strings.NewReader("a + b;"), LexerOptions{BufferSize: 1})
+			var got []string
+			var last Token
+			for _, step := range tt.steps {
+				var token Token
+				var ok bool
+				switch step {
+				case "next":
+					token, ok = scanner.Next()
+					last = token
+				case "peek":
+					token, ok = scanner.Peek()
+				case "unread":
+					scanner.Unread(last)
+					continue
+				}
+				if !ok {
+					token.Value = ""
+				}
+				got = append(got, token.Value)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("valores = %q, se esperaban %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// failingReader devuelve el texto y después un error de lectura
+type failingReader struct {
+	text string
+	err  error
+}
+
+func (r *failingReader) Read(buf []byte) (int, error) {
+	if r.text == "" {
+		return 0, r.err
+	}
+	n := copy(buf, r.text)
+	r.text = r.text[n:]
+	return n, nil
+}
+
+func TestScannerReadError(t *testing.T) {
+	readErr := errors.New("conexión cerrada")
+	scanner := NewScanner(&failingReader{text: "int x", err: readErr})
+	var values []string
+	for token := range scanner.Tokens() {
+		values = append(values, token.Value)
+	}
+	if !errors.Is(scanner.Err(), readErr) {
+		t.Errorf("Err = %v, se esperaba %v", scanner.Err(), readErr)
+	}
+	if strings.Join(values, " ") != "int x" {
+		t.Errorf("tokens antes del error = %q", values)
+	}
+}
+
+// TestJavadocJSON comprueba que el Javadoc de un token se serializa con los
+// nombres de campo, como el resto del token en la respuesta de /analyze
+func TestJavadocJSON(t *testing.T) {
+	tokens := Lex("/**\n * Suma.\n * @param a primero\n */")
+	if len(tokens) != 1 || tokens[0].Doc == nil {
+		t.Fatalf("tokens = %+v, se esperaba un comentario Javadoc", tokens)
+	}
+	data, err := json.Marshal(tokens[0].Doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"Summary":"Suma.","Tags":[{"Name":"param","Arg":"a","Text":"primero"}]}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, se esperaba %s", data, want)
+	}
+}
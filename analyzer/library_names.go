@@ -0,0 +1,91 @@
+// analyzer/library_names.go
+package analyzer
+
+import (
+	"sort"
+	"sync"
+)
+
+// libraryNames nombres conocidos de la biblioteca estándar y de las
+// convenciones del curso que el análisis semántico acepta sin declaración.
+// No son palabras reservadas: el lexer los produce como identificadores.
+var libraryNames = struct {
+	sync.RWMutex
+	names map[string]bool
+}{names: map[string]bool{
+	// java.lang
+	"String":        true,
+	"System":        true,
+	"Object":        true,
+	"Math":          true,
+	"Integer":       true,
+	"Long":          true,
+	"Double":        true,
+	"Float":         true,
+	"Short":         true,
+	"Byte":          true,
+	"Character":     true,
+	"Boolean":       true,
+	"StringBuilder": true,
+	"Thread":        true,
+	"Exception":     true,
+	// Miembros de uso frecuente
+	"out":     true,
+	"err":     true,
+	"in":      true,
+	"println": true,
+	"print":   true,
+	"printf":  true,
+	"equals":  true,
+	"length":  true,
+	// java.util
+	"Scanner":   true,
+	"List":      true,
+	"ArrayList": true,
+	"Map":       true,
+	"HashMap":   true,
+	"Set":       true,
+	"HashSet":   true,
+	"Arrays":    true,
+	// Convenciones de los ejercicios
+	"main":      true,
+	"args":      true,
+	"ejercicio": true,
+}}
+
+// IsLibraryName indica si el nombre está en la tabla de nombres conocidos
+func IsLibraryName(name string) bool {
+	libraryNames.RLock()
+	defer libraryNames.RUnlock()
+	return libraryNames.names[name]
+}
+
+// RegisterLibraryNames agrega nombres a la tabla de nombres conocidos
+func RegisterLibraryNames(names ...string) {
+	libraryNames.Lock()
+	defer libraryNames.Unlock()
+	for _, name := range names {
+		libraryNames.names[name] = true
+	}
+}
+
+// UnregisterLibraryNames quita nombres de la tabla de nombres conocidos
+func UnregisterLibraryNames(names ...string) {
+	libraryNames.Lock()
+	defer libraryNames.Unlock()
+	for _, name := range names {
+		delete(libraryNames.names, name)
+	}
+}
+
+// LibraryNames devuelve la tabla de nombres conocidos ordenada
+func LibraryNames() []string {
+	libraryNames.RLock()
+	defer libraryNames.RUnlock()
+	result := make([]string, 0, len(libraryNames.names))
+	for name := range libraryNames.names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
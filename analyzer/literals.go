@@ -0,0 +1,270 @@
+// analyzer/literals.go
+package analyzer
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// unicodeReader lee caracteres aplicando la traducción de escapes Unicode
+// (\uXXXX) que Java realiza antes del análisis léxico.
+type unicodeReader struct {
+	runes []rune
+	pos   int
+	// backslashes cuenta las '\' literales consecutivas leídas; una '\'
+	// solo inicia un escape Unicode si va precedida de un número par.
+	backslashes int
+	// plain desactiva la traducción cuando el texto ya fue traducido
+	plain bool
+}
+
+// next devuelve el siguiente carácter traducido. problem no está vacío si se
+// encontró un escape Unicode mal formado.
+func (r *unicodeReader) next() (c rune, problem phrase, ok bool) {
+	if r.pos >= len(r.runes) {
+		return 0, phrase{}, false
+	}
+
+	c = r.runes[r.pos]
+	if c != '\\' {
+		r.pos++
+		r.backslashes = 0
+		return c, phrase{}, true
+	}
+
+	if !r.plain && r.backslashes%2 == 0 && r.pos+1 < len(r.runes) && r.runes[r.pos+1] == 'u' {
+		j := r.pos + 1
+		for j < len(r.runes) && r.runes[j] == 'u' {
+			j++
+		}
+		if j+4 > len(r.runes) || !allHex(r.runes[j:j+4]) {
+			r.pos = j
+			r.backslashes = 0
+			return 0, newPhrase("phrase.unicode_escape"), true
+		}
+		var value rune
+		for _, h := range r.runes[j : j+4] {
+			value = value*16 + hexValue(h)
+		}
+		r.pos = j + 4
+		// Un '\' obtenido por traducción no puede iniciar otro escape Unicode
+		r.backslashes = 1
+		return value, phrase{}, true
+	}
+
+	r.pos++
+	r.backslashes++
+	return c, phrase{}, true
+}
+
+// peek devuelve el siguiente carácter sin traducir
+func (r *unicodeReader) peek() (rune, bool) {
+	if r.pos >= len(r.runes) {
+		return 0, false
+	}
+	return r.runes[r.pos], true
+}
+
+// quotedLiteral resultado de reconocer un literal de string o de char
+type quotedLiteral struct {
+	value   string // contenido con los escapes procesados
+	end     int    // posición siguiente al literal en runes
+	closed  bool   // se encontró la comilla de cierre
+	problem phrase // primer escape inválido encontrado
+}
+
+// scanQuoted reconoce un literal delimitado por quote cuya comilla de apertura
+// está en runes[start]. Los literales de una sola línea terminan con error al
+// llegar a un salto de línea.
+func scanQuoted(runes []rune, start int, quote rune) quotedLiteral {
+	reader := &unicodeReader{runes: runes, pos: start + 1}
+	var value strings.Builder
+	result := quotedLiteral{}
+
+	for {
+		before := reader.pos
+		c, problem, ok := reader.next()
+		if !ok || c == '\n' || c == '\r' {
+			result.end = before
+			break
+		}
+		if !problem.empty() {
+			if result.problem.empty() {
+				result.problem = problem
+			}
+			continue
+		}
+		if c == quote {
+			result.end = reader.pos
+			result.closed = true
+			break
+		}
+		if c != '\\' {
+			value.WriteRune(c)
+			continue
+		}
+
+		decoded, problem := readEscape(reader)
+		if !problem.empty() {
+			if result.problem.empty() {
+				result.problem = problem
+			}
+			continue
+		}
+		value.WriteRune(decoded)
+	}
+
+	result.value = value.String()
+	return result
+}
+
+// readEscape decodifica la secuencia que sigue a una '\' (ya consumida)
+func readEscape(reader *unicodeReader) (rune, phrase) {
+	before := reader.pos
+	c, problem, ok := reader.next()
+	if !ok || c == '\n' || c == '\r' {
+		reader.pos = before
+		return 0, newPhrase("phrase.backslash_at_end")
+	}
+	if !problem.empty() {
+		return 0, problem
+	}
+
+	switch c {
+	case 'b':
+		return '\b', phrase{}
+	case 't':
+		return '\t', phrase{}
+	case 'n':
+		return '\n', phrase{}
+	case 'f':
+		return '\f', phrase{}
+	case 'r':
+		return '\r', phrase{}
+	case 's':
+		return ' ', phrase{}
+	case '"', '\'', '\\':
+		return c, phrase{}
+	}
+
+	if c >= '0' && c <= '7' {
+		// Escape octal: \0 a \377
+		value := c - '0'
+		maxDigits := 2
+		if c > '3' {
+			maxDigits = 1
+		}
+		for n := 0; n < maxDigits; n++ {
+			next, ok := reader.peek()
+			if !ok || next < '0' || next > '7' {
+				break
+			}
+			reader.pos++
+			value = value*8 + (next - '0')
+		}
+		return value, phrase{}
+	}
+
+	return 0, newPhrase("phrase.invalid_escape", string(c))
+}
+
+// lexQuoted reconoce un literal de string (comillas dobles) o de char (simples) que empieza
+// en runes[*i] y avanza la posición. El token guarda en Value el contenido
+// decodificado y en Raw el texto original con las comillas.
+func lexQuoted(runes []rune, i *int, line *int, col *int) *Token {
+	start := *i
+	startCol := *col
+	quote := runes[start]
+	if quote == '"' && start+2 < len(runes) && runes[start+1] == '"' && runes[start+2] == '"' {
+		return lexTextBlock(runes, i, line, col)
+	}
+	literal := scanQuoted(runes, start, quote)
+
+	*col += literal.end - start
+	*i = literal.end
+	raw := string(runes[start:literal.end])
+
+	if quote == '"' {
+		switch {
+		case !literal.closed:
+			return lexicalError("LEX002", *line, startCol, raw)
+		case !literal.problem.empty():
+			return lexicalError("LEX003", *line, startCol, raw, literal.problem)
+		}
+		return &Token{Type: KindString, Value: literal.value, Line: *line, Col: startCol, Raw: raw}
+	}
+
+	switch {
+	case !literal.closed:
+		return lexicalError("LEX004", *line, startCol, raw)
+	case !literal.problem.empty():
+		return lexicalError("LEX005", *line, startCol, raw, literal.problem)
+	case literal.value == "":
+		return lexicalError("LEX006", *line, startCol, raw)
+	case len(utf16.Encode([]rune(literal.value))) > 1:
+		return lexicalError("LEX007", *line, startCol, raw)
+	}
+	return &Token{Type: KindChar, Value: literal.value, Line: *line, Col: startCol, Raw: raw}
+}
+
+// decodeEscapes procesa los escapes del contenido de un string (sin comillas)
+func decodeEscapes(s string) (string, phrase) {
+	runes := []rune(s)
+	reader := &unicodeReader{runes: runes}
+	var value strings.Builder
+	for {
+		c, problem, ok := reader.next()
+		if !ok {
+			return value.String(), phrase{}
+		}
+		if !problem.empty() {
+			return "", problem
+		}
+		if c != '\\' {
+			value.WriteRune(c)
+			continue
+		}
+		decoded, problem := readEscape(reader)
+		if !problem.empty() {
+			return "", problem
+		}
+		value.WriteRune(decoded)
+	}
+}
+
+// charLiteralValue devuelve el carácter de un token char
+func charLiteralValue(token Token) (rune, bool) {
+	if token.Type != KindChar || utf8.RuneCountInString(token.Value) != 1 {
+		return 0, false
+	}
+	r, _ := utf8.DecodeRuneInString(token.Value)
+	return r, true
+}
+
+// sourceText devuelve el texto de un literal tal y como aparece en el código, sin comillas
+func sourceText(token Token) string {
+	if len(token.Raw) >= 2 {
+		return token.Raw[1 : len(token.Raw)-1]
+	}
+	return token.Value
+}
+
+func allHex(runes []rune) bool {
+	for _, r := range runes {
+		if !isHexDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func hexValue(r rune) rune {
+	switch {
+	case r >= '0' && r <= '9':
+		return r - '0'
+	case r >= 'a' && r <= 'f':
+		return r - 'a' + 10
+	}
+	return r - 'A' + 10
+}
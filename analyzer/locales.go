@@ -0,0 +1,271 @@
+// analyzer/locales.go
+package analyzer
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLocale idioma de los mensajes cuando no se pide otro o el pedido no
+// tiene catálogo
+const DefaultLocale = "es"
+
+// localeFiles catálogos que se distribuyen con el analizador, uno por idioma
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// catalogs mensajes de cada idioma por clave: los códigos de diagnóstico, sus
+// etiquetas y notas, los formatos de texto ("format.error") y las frases que
+// se usan como argumento ("phrase.quoted")
+var catalogs = struct {
+	sync.RWMutex
+	messages map[string]map[string]string
+}{messages: map[string]map[string]string{}}
+
+func init() {
+	entries, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		data, err := localeFiles.ReadFile("locales/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		if err := addCatalog(entry.Name(), data); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// LoadLocales añade los catálogos <idioma>.json del directorio. Un idioma
+// que ya existe se completa: sus claves sustituyen a las del catálogo
+// distribuido.
+func LoadLocales(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := addCatalog(filepath.Base(file), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addCatalog mezcla el catálogo del fichero name en el de su idioma
+func addCatalog(name string, data []byte) error {
+	var entries map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("catálogo %s: %v", name, err)
+	}
+	locale := strings.ToLower(strings.TrimSuffix(name, filepath.Ext(name)))
+
+	catalogs.Lock()
+	defer catalogs.Unlock()
+	catalog, ok := catalogs.messages[locale]
+	if !ok {
+		catalog = map[string]string{}
+		catalogs.messages[locale] = catalog
+	}
+	for key, text := range entries {
+		catalog[key] = text
+	}
+	return nil
+}
+
+// Locales devuelve los idiomas con catálogo, ordenados
+func Locales() []string {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+	locales := make([]string, 0, len(catalogs.messages))
+	for locale := range catalogs.messages {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// formatVerb argumento de un mensaje del catálogo: %s o %[n]s cuando la
+// traducción cambia el orden
+var formatVerb = regexp.MustCompile(`%(\[\d+\])?s`)
+
+// CheckCatalogs comprueba que todos los idiomas traducen las mismas claves
+// que el catálogo por defecto y con el mismo número de argumentos
+func CheckCatalogs() error {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+	reference := catalogs.messages[DefaultLocale]
+	var problems []string
+	for locale, catalog := range catalogs.messages {
+		for key, text := range reference {
+			translation, ok := catalog[key]
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: falta la clave %s", locale, key))
+				continue
+			}
+			if countArgs(translation) != countArgs(text) {
+				problems = append(problems, fmt.Sprintf("%s: %s tiene %d argumentos, se esperaban %d",
+					locale, key, countArgs(translation), countArgs(text)))
+			}
+		}
+		for key := range catalog {
+			if _, ok := reference[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: clave desconocida %s", locale, key))
+			}
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("catálogos incompletos:\n%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// countArgs número de argumentos distintos que usa un mensaje
+func countArgs(text string) int {
+	count := 0
+	for _, verb := range formatVerb.FindAllStringSubmatch(text, -1) {
+		if verb[1] == "" {
+			count++
+			continue
+		}
+		if n, _ := strconv.Atoi(strings.Trim(verb[1], "[]")); n > count {
+			count = n
+		}
+	}
+	return count
+}
+
+// MatchLocale elige el idioma de los mensajes. Cada preferencia puede ser un
+// idioma ("en") o una cabecera Accept-Language ("en-US,en;q=0.9,es;q=0.8");
+// gana la primera preferencia con algún idioma disponible.
+func MatchLocale(preferences ...string) string {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+	for _, preference := range preferences {
+		for _, locale := range parseAcceptLanguage(preference) {
+			if _, ok := catalogs.messages[locale]; ok {
+				return locale
+			}
+			if base, _, found := strings.Cut(locale, "-"); found {
+				if _, ok := catalogs.messages[base]; ok {
+					return base
+				}
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// parseAcceptLanguage devuelve los idiomas de la cabecera ordenados por su
+// peso q, sin los de peso 0
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		locale = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(locale, "_", "-")))
+		if locale == "" || locale == "*" {
+			continue
+		}
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > 0 {
+			ranges = append(ranges, weighted{locale, q})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+	locales := make([]string, len(ranges))
+	for i, r := range ranges {
+		locales[i] = r.locale
+	}
+	return locales
+}
+
+// phrase fragmento de mensaje que se traduce con el diagnóstico, como "un
+// identificador" o "sufijo no válido". Sin clave, el fragmento es texto que
+// no se traduce; la phrase vacía indica que no hay fragmento.
+type phrase struct {
+	key  string
+	args []interface{}
+}
+
+// empty indica que no hay fragmento
+func (p phrase) empty() bool {
+	return p.key == "" && len(p.args) == 0
+}
+
+// newPhrase crea la frase key del catálogo con sus argumentos
+func newPhrase(key string, args ...interface{}) phrase {
+	return phrase{key: key, args: args}
+}
+
+// verbatim fragmento que se escribe igual en todos los idiomas, como el
+// nombre de un tipo
+func verbatim(text string) phrase {
+	return phrase{args: []interface{}{text}}
+}
+
+// quoted texto literal de código entre comillas simples
+func quoted(text string) phrase {
+	return newPhrase("phrase.quoted", text)
+}
+
+// translate devuelve el mensaje key en el idioma locale con los argumentos
+// sustituidos; si el idioma no lo traduce se usa el idioma por defecto
+func translate(locale, key string, args []interface{}) string {
+	catalogs.RLock()
+	format, ok := catalogs.messages[locale][key]
+	if !ok {
+		format, ok = catalogs.messages[DefaultLocale][key]
+	}
+	catalogs.RUnlock()
+	if !ok {
+		return key
+	}
+	return fmt.Sprintf(format, renderArgs(locale, args)...)
+}
+
+// renderArgs convierte los argumentos a texto en el idioma locale
+func renderArgs(locale string, args []interface{}) []interface{} {
+	texts := make([]interface{}, len(args))
+	for i, arg := range args {
+		texts[i] = renderArg(locale, arg)
+	}
+	return texts
+}
+
+// renderArg convierte un argumento a texto, traduciendo las frases
+func renderArg(locale string, arg interface{}) string {
+	if p, ok := arg.(phrase); ok {
+		if p.key == "" {
+			return fmt.Sprint(p.args...)
+		}
+		return translate(locale, p.key, p.args)
+	}
+	return fmt.Sprint(arg)
+}
@@ -0,0 +1,81 @@
+// analyzer/locales_test.go
+package analyzer
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// messageKey literal del código que es una clave del catálogo: un código de
+// diagnóstico, sus etiquetas y notas ("SEM002.previous") o una frase
+var messageKey = regexp.MustCompile(`^((LEX|SYN|SEM)\d{3}(\.[a-z_]+)?|phrase\.[a-z_]+)$`)
+
+// usedMessageKeys recoge las claves del catálogo que usa el código del
+// paquete, más las que se construyen al vuelo
+func usedMessageKeys(t *testing.T) map[string]bool {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if text, err := strconv.Unquote(lit.Value); err == nil && messageKey.MatchString(text) {
+					keys[text] = true
+				}
+			}
+			return true
+		})
+	}
+	// "format." + gravedad en Diagnostic.String y "phrase." + clase de tipo
+	// en describeTypeKind
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityInfo, SeverityHint} {
+		keys["format."+string(severity)] = true
+	}
+	for _, kind := range []string{"class", "interface", "enum", "record"} {
+		keys["phrase."+kind] = true
+	}
+	return keys
+}
+
+func TestLocalesTranslateUsedKeys(t *testing.T) {
+	keys := usedMessageKeys(t)
+	if len(keys) == 0 {
+		t.Fatal("no se encontró ninguna clave en el código")
+	}
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+	for _, locale := range []string{"es", "en"} {
+		catalog, ok := catalogs.messages[locale]
+		if !ok {
+			t.Fatalf("falta el catálogo %s", locale)
+		}
+		for key := range keys {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("%s: falta la clave %s", locale, key)
+			}
+		}
+	}
+}
+
+func TestCheckCatalogs(t *testing.T) {
+	if err := CheckCatalogs(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,370 @@
+// analyzer/numbers.go
+package analyzer
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// startsNumber indica si en runes[i] empieza un literal numérico ("12", ".5")
+func startsNumber(runes []rune, i int) bool {
+	if isDigit(runes[i]) {
+		return true
+	}
+	return runes[i] == '.' && i+1 < len(runes) && isDigit(runes[i+1])
+}
+
+// lexNumber reconoce un literal numérico de Java (decimal, hexadecimal, octal o
+// binario, con separadores '_', exponentes y sufijos) que empieza en runes[*i].
+func lexNumber(runes []rune, i *int, col *int, line int) *Token {
+	start := *i
+	startCol := *col
+	kind, problem := scanNumber(runes, i)
+
+	// Letras pegadas al número: 123abc, 0x1G, 10UL
+	if *i < len(runes) && isIdentifierPart(runes[*i]) {
+		for *i < len(runes) && isIdentifierPart(runes[*i]) {
+			*i++
+		}
+		if problem.empty() {
+			problem = newPhrase("phrase.invalid_suffix")
+		}
+	}
+
+	*col += *i - start
+	text := string(runes[start:*i])
+	if !problem.empty() {
+		return lexicalError("LEX008", line, startCol, "", text, problem)
+	}
+	return &Token{Type: kind, Value: text, Line: line, Col: startCol}
+}
+
+// scanNumber avanza sobre el literal y devuelve su tipo, o la descripción del
+// problema si el literal está mal formado.
+func scanNumber(runes []rune, i *int) (TokenKind, phrase) {
+	at := func(k int) rune {
+		if *i+k < len(runes) {
+			return runes[*i+k]
+		}
+		return 0
+	}
+
+	if at(0) == '0' && (at(1) == 'x' || at(1) == 'X') {
+		*i += 2
+		return scanHexNumber(runes, i)
+	}
+
+	if at(0) == '0' && (at(1) == 'b' || at(1) == 'B') {
+		*i += 2
+		digits := scanDigits(runes, i, isDigit)
+		if digits == "" {
+			return KindError, newPhrase("phrase.binary_digits_missing")
+		}
+		if problem := underscoreProblem(digits); !problem.empty() {
+			return KindError, problem
+		}
+		if strings.IndexFunc(digits, func(r rune) bool { return r != '0' && r != '1' && r != '_' }) >= 0 {
+			return KindError, newPhrase("phrase.binary_digit_invalid")
+		}
+		return integerSuffix(runes, i)
+	}
+
+	// Literal decimal (u octal si empieza por 0)
+	intPart := ""
+	if at(0) != '.' {
+		intPart = scanDigits(runes, i, isDigit)
+	}
+
+	floating := false
+	if at(0) == '.' && at(1) != '.' {
+		if strings.HasSuffix(intPart, "_") {
+			return KindError, newPhrase("phrase.underscore_before_point")
+		}
+		floating = true
+		*i++
+		if at(0) == '_' {
+			return KindError, newPhrase("phrase.underscore_after_point")
+		}
+		frac := scanDigits(runes, i, isDigit)
+		if strings.HasSuffix(frac, "_") {
+			return KindError, newPhrase("phrase.underscore_at_end")
+		}
+	}
+
+	if at(0) == 'e' || at(0) == 'E' {
+		if strings.HasSuffix(intPart, "_") {
+			return KindError, newPhrase("phrase.underscore_before_exponent")
+		}
+		floating = true
+		*i++
+		if problem := scanExponent(runes, i); !problem.empty() {
+			return KindError, problem
+		}
+	}
+
+	switch at(0) {
+	case 'f', 'F', 'd', 'D':
+		if strings.HasSuffix(intPart, "_") {
+			return KindError, newPhrase("phrase.underscore_before_suffix")
+		}
+		*i++
+		if at(-1) == 'f' || at(-1) == 'F' {
+			return KindFloat, phrase{}
+		}
+		return KindDouble, phrase{}
+	}
+	if floating {
+		return KindDouble, phrase{}
+	}
+
+	if problem := underscoreProblem(intPart); !problem.empty() {
+		return KindError, problem
+	}
+	if len(intPart) > 1 && intPart[0] == '0' && strings.ContainsAny(intPart, "89") {
+		return KindError, newPhrase("phrase.octal_digit_invalid")
+	}
+	return integerSuffix(runes, i)
+}
+
+// scanHexNumber reconoce la parte posterior a "0x": entero o flotante hexadecimal
+func scanHexNumber(runes []rune, i *int) (TokenKind, phrase) {
+	digits := scanDigits(runes, i, isHexDigit)
+	if problem := underscoreProblem(digits); !problem.empty() {
+		return KindError, problem
+	}
+
+	floating := false
+	if *i < len(runes) && runes[*i] == '.' {
+		floating = true
+		*i++
+		frac := scanDigits(runes, i, isHexDigit)
+		if problem := underscoreProblem(frac); !problem.empty() {
+			return KindError, problem
+		}
+		digits += frac
+	}
+	if digits == "" {
+		return KindError, newPhrase("phrase.hex_digits_missing")
+	}
+
+	if *i < len(runes) && (runes[*i] == 'p' || runes[*i] == 'P') {
+		*i++
+		if problem := scanExponent(runes, i); !problem.empty() {
+			return KindError, problem
+		}
+		if *i < len(runes) {
+			switch runes[*i] {
+			case 'f', 'F':
+				*i++
+				return KindFloat, phrase{}
+			case 'd', 'D':
+				*i++
+			}
+		}
+		return KindDouble, phrase{}
+	}
+	if floating {
+		return KindError, newPhrase("phrase.hex_exponent_missing")
+	}
+	return integerSuffix(runes, i)
+}
+
+// scanExponent reconoce el signo y los dígitos de un exponente
+func scanExponent(runes []rune, i *int) phrase {
+	if *i < len(runes) && (runes[*i] == '+' || runes[*i] == '-') {
+		*i++
+	}
+	digits := scanDigits(runes, i, isDigit)
+	if digits == "" {
+		return newPhrase("phrase.exponent_missing")
+	}
+	return underscoreProblem(digits)
+}
+
+// integerSuffix consume el sufijo 'L' opcional de un literal entero
+func integerSuffix(runes []rune, i *int) (TokenKind, phrase) {
+	if *i < len(runes) && (runes[*i] == 'l' || runes[*i] == 'L') {
+		*i++
+		return KindLong, phrase{}
+	}
+	return KindNumber, phrase{}
+}
+
+// scanDigits consume una secuencia de dígitos válidos y separadores '_'
+func scanDigits(runes []rune, i *int, valid func(rune) bool) string {
+	start := *i
+	for *i < len(runes) && (valid(runes[*i]) || runes[*i] == '_') {
+		*i++
+	}
+	return string(runes[start:*i])
+}
+
+// underscoreProblem comprueba que los '_' estén solo entre dígitos
+func underscoreProblem(digits string) phrase {
+	if strings.HasPrefix(digits, "_") {
+		return newPhrase("phrase.underscore_at_start")
+	}
+	if strings.HasSuffix(digits, "_") {
+		return newPhrase("phrase.underscore_at_end")
+	}
+	return phrase{}
+}
+
+func isHexDigit(c rune) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+var (
+	errIntOutOfRange    = errors.New("fuera del rango de int")
+	errLongOutOfRange   = errors.New("fuera del rango de long")
+	errFloatOutOfRange  = errors.New("fuera del rango de float")
+	errDoubleOutOfRange = errors.New("fuera del rango de double")
+)
+
+// parseIntegerLiteral devuelve el valor de un literal int o long comprobando su
+// rango. negated indica que va precedido de un '-' unario, lo que permite
+// 2147483648 y 9223372036854775808L.
+func parseIntegerLiteral(text string, negated bool) (int64, error) {
+	digits := strings.ReplaceAll(text, "_", "")
+	isLong := strings.HasSuffix(digits, "l") || strings.HasSuffix(digits, "L")
+	if isLong {
+		digits = digits[:len(digits)-1]
+	}
+
+	base := 10
+	lower := strings.ToLower(digits)
+	switch {
+	case strings.HasPrefix(lower, "0x"):
+		base, digits = 16, digits[2:]
+	case strings.HasPrefix(lower, "0b"):
+		base, digits = 2, digits[2:]
+	case len(digits) > 1 && digits[0] == '0':
+		base, digits = 8, digits[1:]
+	}
+
+	rangeErr := errIntOutOfRange
+	bits := 32
+	if isLong {
+		rangeErr = errLongOutOfRange
+		bits = 64
+	}
+
+	value, err := strconv.ParseUint(digits, base, 64)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, rangeErr
+		}
+		return 0, err
+	}
+
+	if base == 10 {
+		limit := uint64(1)<<(bits-1) - 1
+		if negated {
+			limit++
+		}
+		if value > limit {
+			return 0, rangeErr
+		}
+		if value == limit && negated {
+			if bits == 32 {
+				return math.MinInt32, nil
+			}
+			return math.MinInt64, nil
+		}
+		if negated {
+			return -int64(value), nil
+		}
+		return int64(value), nil
+	}
+
+	// Hexadecimal, octal y binario representan los bits en complemento a dos
+	if bits == 32 {
+		if value > math.MaxUint32 {
+			return 0, rangeErr
+		}
+		return int64(int32(uint32(value))), nil
+	}
+	return int64(value), nil
+}
+
+// parseFloatLiteral devuelve el valor de un literal float o double comprobando
+// que no desborde ni se redondee a cero.
+func parseFloatLiteral(text string) (float64, error) {
+	digits := strings.ReplaceAll(text, "_", "")
+	lower := strings.ToLower(digits)
+	isHex := strings.HasPrefix(lower, "0x")
+
+	bitSize := 64
+	rangeErr := errDoubleOutOfRange
+	last := lower[len(lower)-1]
+	if (last == 'f' || last == 'd') && (!isHex || strings.Contains(lower, "p")) {
+		if last == 'f' {
+			bitSize = 32
+			rangeErr = errFloatOutOfRange
+		}
+		digits = digits[:len(digits)-1]
+	}
+
+	value, err := strconv.ParseFloat(digits, bitSize)
+	if err != nil {
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, rangeErr
+		}
+		return 0, err
+	}
+
+	// Un literal distinto de cero no puede redondearse a cero
+	if value == 0 && hasNonZeroMantissa(lower, isHex) {
+		return 0, rangeErr
+	}
+	return value, nil
+}
+
+// hasNonZeroMantissa indica si la mantisa de un literal flotante tiene algún dígito distinto de cero
+func hasNonZeroMantissa(lower string, isHex bool) bool {
+	mantissa := lower
+	if isHex {
+		mantissa = mantissa[2:]
+		if p := strings.IndexByte(mantissa, 'p'); p >= 0 {
+			mantissa = mantissa[:p]
+		}
+	} else if e := strings.IndexByte(mantissa, 'e'); e >= 0 {
+		mantissa = mantissa[:e]
+	}
+	return strings.IndexFunc(mantissa, func(r rune) bool {
+		return r != '0' && r != '.' && r != '_' && r != 'f' && r != 'd'
+	}) >= 0
+}
+
+// validateNumericLiterals comprueba que cada literal numérico quepa en su
+// tipo. Un literal entero precedido directamente de un '-' unario, como en
+// "-2147483648" o "(int) -2147483648", admite el valor mínimo de su tipo.
+func validateNumericLiterals(unit *CompilationUnit) []Diagnostic {
+	errors := []Diagnostic{}
+
+	negated := make(map[*Literal]bool)
+	Inspect(unit, func(node Node) bool {
+		switch n := node.(type) {
+		case *UnaryExpr:
+			if lit, ok := n.X.(*Literal); ok && n.Op == OpSub && !n.Postfix {
+				negated[lit] = true
+			}
+		case *Literal:
+			switch n.Kind {
+			case KindNumber, KindLong:
+				if _, err := parseIntegerLiteral(n.Value, negated[n]); err != nil {
+					errors = append(errors, newDiagnostic("SEM124", SeverityError, n, n.Value, literalType(n.Kind)))
+				}
+			case KindFloat, KindDouble:
+				if _, err := parseFloatLiteral(n.Value); err != nil {
+					errors = append(errors, newDiagnostic("SEM125", SeverityError, n, n.Value, literalType(n.Kind)))
+				}
+			}
+		}
+		return true
+	})
+
+	return errors
+}
@@ -0,0 +1,36 @@
+// analyzer/numbers_test.go
+package analyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIntegerLiteralRangeAfterOperand(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"-2147483648", nil},
+		{"1 - -2147483648", nil},
+		{"(-2147483648)", nil},
+		{"(int) -2147483648", nil},
+		{"(long) -9223372036854775808L", nil},
+		{"-(2147483648)", []string{"SEM124"}},
+		{"(int) 2147483648", []string{"SEM124"}},
+		{"a[0] - 2147483648", []string{"SEM124"}},
+		{"(a[0]) - 2147483648", []string{"SEM124"}},
+		{"n++ - 2147483648", []string{"SEM124"}},
+		{"n-- - 2147483648", []string{"SEM124"}},
+		{"n - 2147483648", []string{"SEM124"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			code := "class A { void m(int[] a, int n) { long x = " + tt.expr + "; } }"
+			got := semanticCodes(t, code)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("códigos = %v, se esperaban %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// analyzer/operators.go
+package analyzer
+
+// maxPunctuationLength longitud del operador más largo (>>>=)
+const maxPunctuationLength = 4
+
+// lexOperator reconoce el operador o separador más largo que empieza en
+// runes[*i] (regla de la coincidencia máxima): "a+++b" produce "++" y "+",
+// ">>>=" un solo token. Devuelve nil si el carácter no es de puntuación.
+func lexOperator(runes []rune, i *int, col *int, line int) *Token {
+	for n := maxPunctuationLength; n >= 1; n-- {
+		if *i+n > len(runes) {
+			continue
+		}
+		text := string(runes[*i : *i+n])
+		kind, ok := operators[text]
+		if !ok {
+			kind, ok = separators[text]
+		}
+		if !ok {
+			continue
+		}
+		token := &Token{Type: kind, Value: text, Line: line, Col: *col}
+		*i += n
+		*col += n
+		return token
+	}
+	return nil
+}
+
+// greaterSplits operadores que empiezan por '>' y lo que queda al separar el primero
+var greaterSplits = map[TokenKind]TokenKind{
+	OpShiftRight:               OpGreater,
+	OpUnsignedShiftRight:       OpShiftRight,
+	OpGreaterEqual:             OpAssign,
+	OpShiftRightAssign:         OpGreaterEqual,
+	OpUnsignedShiftRightAssign: OpShiftRightAssign,
+}
+
+// splitGreater separa el primer '>' de un token ">>", ">>>", ">=", ">>=" o
+// ">>>=". El lexer aplica la coincidencia máxima, así que el cierre de
+// argumentos genéricos anidados (List<List<String>>) llega como ">>" y es el
+// parser quien debe partirlo. ok es false si el token no empieza por '>'.
+func splitGreater(token Token) (first Token, rest Token, ok bool) {
+	restKind, ok := greaterSplits[token.Type]
+	if !ok {
+		return token, Token{}, false
+	}
+	first = token
+	first.Type, first.Value = OpGreater, ">"
+	first.EndCol, first.EndOffset, first.EndOffset16 = token.Col+1, token.Offset+1, token.Offset16+1
+	rest = token
+	rest.Type, rest.Value = restKind, token.Value[1:]
+	rest.Col, rest.Offset, rest.Offset16 = token.Col+1, token.Offset+1, token.Offset16+1
+	return first, rest, true
+}
@@ -1,506 +1,828 @@
 // analyzer/parser.go
 package analyzer
 
-import (
-	"fmt"
-	"strings"
-)
+import "strings"
 
+// Parser analizador sintáctico descendente recursivo que construye el árbol
+// de una unidad de compilación
 type Parser struct {
 	tokens []Token
 	pos    int
-	errors []string
-}
-
-func Parse(tokens []Token) (bool, []string) {
-	parser := &Parser{tokens: tokens, pos: 0, errors: []string{}}
-	parser.validateBrackets()
-	parser.validateStatements()
-	parser.validateStrings()
-	parser.validateSemicolons()
-	return len(parser.errors) == 0, parser.errors
-}
-
-func (p *Parser) validateBrackets() {
-	parenStack := []Token{}
-	braceStack := []Token{}
-
-	for _, token := range p.tokens {
-		switch token.Type {
-		case "lparen":
-			parenStack = append(parenStack, token)
-		case "rparen":
-			if len(parenStack) == 0 {
-				p.errors = append(p.errors, fmt.Sprintf("Error línea %d: ')' sin '(' correspondiente", token.Line))
-			} else {
-				parenStack = parenStack[:len(parenStack)-1]
-			}
-		case "lbrace":
-			braceStack = append(braceStack, token)
-		case "rbrace":
-			if len(braceStack) == 0 {
-				p.errors = append(p.errors, fmt.Sprintf("Error línea %d: '}' sin '{' correspondiente", token.Line))
-			} else {
-				braceStack = braceStack[:len(braceStack)-1]
-			}
-		}
-	}
+	// diagnostics errores léxicos y sintácticos encontrados
+	diagnostics []Diagnostic
+	// lastErrorPos evita reportar dos errores en la misma posición, salvo un
+	// error sobre el token actual tras uno que señala lo que falta antes de
+	// él (lastErrorBefore), como la '}' sobrante tras un ';' que falta
+	lastErrorPos    int
+	lastErrorBefore bool
+	// syntaxErrors errores sintácticos reportados, que se cuentan aparte de
+	// los léxicos para que unos no oculten a los otros
+	syntaxErrors int
+	// truncated indica que se alcanzó maxParseErrors y se omiten los demás
+	truncated bool
+	// caseLabel indica que se analiza la etiqueta de un case, donde "x ->"
+	// separa la etiqueta del cuerpo en lugar de empezar una lambda
+	caseLabel bool
+	// typeVars parámetros de tipo en ámbito, de las clases y métodos que
+	// encierran la posición actual
+	typeVars []string
+}
 
-	for _, token := range parenStack {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: '(' sin cerrar", token.Line))
-	}
+// maxParseErrors errores léxicos y errores sintácticos que se reportan como
+// máximo: pasado ese punto casi todos son consecuencia de los primeros
+const maxParseErrors = 10
+
+// syncKeywords palabras reservadas que empiezan una sentencia o una
+// declaración, donde el parser puede continuar tras un error
+var syncKeywords = map[TokenKind]bool{
+	KwIf: true, KwFor: true, KwWhile: true, KwDo: true, KwSwitch: true,
+	KwCase: true, KwDefault: true, KwReturn: true, KwBreak: true,
+	KwContinue: true, KwThrow: true, KwTry: true, KwAssert: true,
+	KwClass: true, KwInterface: true, KwEnum: true, KwImport: true,
+	KwPackage: true, KwPublic: true, KwPrivate: true, KwProtected: true,
+	KwStatic: true, KwFinal: true, KwAbstract: true,
+}
 
-	for _, token := range braceStack {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: '{' sin cerrar", token.Line))
-	}
+// parseBailout se lanza con panic para abandonar la construcción en curso
+// tras un error; la recupera el bucle de sentencias o de miembros más cercano
+type parseBailout struct{}
+
+// Parse realiza el análisis sintáctico y devuelve solo los diagnósticos
+func Parse(tokens []Token) (bool, []Diagnostic) {
+	_, diagnostics := ParseFile(tokens)
+	return !HasErrors(diagnostics), diagnostics
+}
+
+// ParseMessages es la forma anterior de Parse, que devuelve los
+// diagnósticos en el formato de texto, como "Error línea 3: ..."
+func ParseMessages(tokens []Token) (bool, []string) {
+	ok, diagnostics := Parse(tokens)
+	return ok, Messages(diagnostics)
 }
 
-// Nueva función para validar strings mal formados
-func (p *Parser) validateStrings() {
-	for _, token := range p.tokens {
-		if token.Type == "error" {
-			if strings.Contains(token.Value, "String sin cerrar") {
-				p.errors = append(p.errors, fmt.Sprintf("Error línea %d: String sin cerrar - falta comilla de cierre", token.Line))
+// ParseFile construye el árbol de una unidad de compilación. Acepta tanto
+// archivos completos como fragmentos con métodos y sentencias sueltas. Los
+// diagnósticos, léxicos y sintácticos, salen en el orden del código.
+func ParseFile(tokens []Token) (*CompilationUnit, []Diagnostic) {
+	parser := NewParser(tokens)
+	unit := parser.parseCompilationUnit()
+	SortDiagnostics(parser.diagnostics)
+	return unit, parser.diagnostics
+}
+
+// NewParser crea un parser sobre los tokens del lexer. Los errores léxicos se
+// reportan aquí y los tokens que no pueden formar parte del árbol se descartan.
+func NewParser(tokens []Token) *Parser {
+	parser := &Parser{diagnostics: []Diagnostic{}, lastErrorPos: -1}
+	lexicalErrors := 0
+	for _, token := range significantTokens(tokens) {
+		if token.Diagnostic != nil {
+			switch {
+			case lexicalErrors < maxParseErrors:
+				parser.diagnostics = append(parser.diagnostics, *token.Diagnostic)
+			case lexicalErrors == maxParseErrors:
+				parser.diagnostics = append(parser.diagnostics, newDiagnostic("LEX013", SeverityError, token.Diagnostic.Span))
 			}
+			lexicalErrors++
+		}
+		// Los literales mal formados ocupan su lugar en el árbol; los
+		// caracteres no válidos y los comentarios sin cerrar se descartan
+		if token.Type == KindUnknown || token.Diagnostic != nil && token.Diagnostic.Code == "LEX009" {
+			continue
 		}
+		parser.tokens = append(parser.tokens, token)
 	}
+	return parser
 }
 
-// Nueva función para validar punto y coma faltante
-func (p *Parser) validateSemicolons() {
-	for i := 0; i < len(p.tokens); i++ {
-		// Detectar System.out.println o System.out.print
-		if p.tokens[i].Value == "System" && i+4 < len(p.tokens) &&
-		   p.tokens[i+1].Value == "." && p.tokens[i+2].Value == "out" &&
-		   p.tokens[i+3].Value == "." && 
-		   (p.tokens[i+4].Value == "println" || p.tokens[i+4].Value == "print") {
-			
-			// Buscar el final de la declaración println/print
-			endPos := p.findPrintStatementEnd(i)
-			if endPos != -1 {
-				// Verificar si hay punto y coma después
-				if endPos+1 >= len(p.tokens) || p.tokens[endPos+1].Type != "semicolon" {
-					p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta ';' después de la declaración System.out.%s", p.tokens[i+4].Line, p.tokens[i+4].Value))
-				}
-			}
+// parseCompilationUnit analiza el archivo completo
+func (p *Parser) parseCompilationUnit() *CompilationUnit {
+	unit := &CompilationUnit{}
+	if len(p.tokens) == 0 {
+		return unit
+	}
+	first := p.peek()
+
+	p.recover(func() {
+		// Las anotaciones iniciales son del paquete (package-info.java) o de
+		// la primera clase, que las vuelve a leer como modificadores
+		if i := p.skipAnnotations(p.pos); i < len(p.tokens) && p.tokens[i].Type == KwPackage {
+			annotations := p.parseAnnotations()
+			unit.Package = p.parsePackageDecl()
+			unit.Package.Annotations = annotations
 		}
-
-		// Detectar println o print simple
-		if (p.tokens[i].Value == "println" || p.tokens[i].Value == "print") {
-			// Verificar que no sea parte de System.out.println
-			isSystemOut := false
-			if i >= 4 && p.tokens[i-4].Value == "System" && p.tokens[i-3].Value == "." &&
-			   p.tokens[i-2].Value == "out" && p.tokens[i-1].Value == "." {
-				isSystemOut = true
-			}
-
-			if !isSystemOut {
-				endPos := p.findPrintStatementEnd(i)
-				if endPos != -1 {
-					if endPos+1 >= len(p.tokens) || p.tokens[endPos+1].Type != "semicolon" {
-						p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta ';' después de la declaración %s", p.tokens[i].Line, p.tokens[i].Value))
-					}
-				}
-			}
+	})
+	for p.at(KwImport) {
+		p.recover(func() {
+			unit.Imports = append(unit.Imports, p.parseImportDecl())
+		})
+	}
+	if p.atModuleDecl() {
+		p.recover(func() {
+			annotations := p.parseAnnotations()
+			unit.Module = p.parseModuleDecl()
+			unit.Module.Annotations = annotations
+		})
+	}
+	for !p.atEnd() {
+		bad := p.recover(func() {
+			p.parseTopLevel(unit)
+		})
+		if bad != nil {
+			unit.Statements = append(unit.Statements, bad)
 		}
+	}
 
-		// Validar declaraciones de variables (int, char)
-		if p.tokens[i].Type == "keyword" && (p.tokens[i].Value == "int" || p.tokens[i].Value == "char") {
-			endPos := p.findVariableDeclarationEnd(i)
-			if endPos != -1 {
-				if endPos+1 >= len(p.tokens) || p.tokens[endPos+1].Type != "semicolon" {
-					p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta ';' después de la declaración de variable", p.tokens[i].Line))
-				}
-			}
-		}
+	unit.Span = p.spanFrom(first)
+	return unit
+}
 
-		// Validar asignaciones simples (variable = valor)
-		if p.tokens[i].Type == "identifier" && i+1 < len(p.tokens) && p.tokens[i+1].Value == "=" {
-			// Verificar que no es parte de una declaración de variable
-			isDeclaration := false
-			if i > 0 && p.tokens[i-1].Type == "keyword" && 
-			   (p.tokens[i-1].Value == "int" || p.tokens[i-1].Value == "char") {
-				isDeclaration = true
-			}
+// parseTopLevel analiza una declaración de tipo, un método suelto o una sentencia
+func (p *Parser) parseTopLevel(unit *CompilationUnit) {
+	if p.at(KindRBrace) {
+		p.errorf(p.peek(), "SYN001")
+		p.next()
+		return
+	}
 
-			if !isDeclaration {
-				endPos := p.findAssignmentEnd(i)
-				if endPos != -1 {
-					if endPos+1 >= len(p.tokens) || p.tokens[endPos+1].Type != "semicolon" {
-						p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta ';' después de la asignación", p.tokens[i].Line))
-					}
-				}
-			}
-		}
+	switch p.peek().Type {
+	case KwPackage:
+		p.errorf(p.peek(), "SYN002")
+		p.parsePackageDecl()
+		return
+	case KwImport:
+		p.errorf(p.peek(), "SYN003")
+		unit.Imports = append(unit.Imports, p.parseImportDecl())
+		return
 	}
-}
 
-// Función auxiliar para encontrar el final de una declaración print
-func (p *Parser) findPrintStatementEnd(start int) int {
-	// Buscar el paréntesis de apertura
-	parenStart := -1
-	for i := start; i < len(p.tokens) && i < start+10; i++ {
-		if p.tokens[i].Type == "lparen" {
-			parenStart = i
-			break
-		}
+	start := p.pos
+	modifiers := p.parseModifiers()
+	switch {
+	case p.atTypeDeclStart():
+		unit.Types = append(unit.Types, p.parseTypeDecl(p.tokens[start], modifiers))
+	case p.atMethodDeclStart():
+		unit.Methods = append(unit.Methods, p.parseMethodDecl(p.tokens[start], modifiers, ""))
+	default:
+		// Sentencia suelta: volver a leer los modificadores como parte de ella
+		p.pos = start
+		unit.Statements = append(unit.Statements, p.parseBlockStatement())
 	}
+}
+
+// parsePackageDecl analiza "package a.b.c;"
+func (p *Parser) parsePackageDecl() *PackageDecl {
+	start := p.next()
+	name := p.parseQualifiedName("SYN106", "package")
+	p.expectSemicolon(newPhrase("phrase.package_decl"))
+	return &PackageDecl{Span: p.spanFrom(start), Name: name}
+}
+
+// parseImportDecl analiza "import a.b.C;", "import a.b.*;",
+// "import static a.b.C.m;" o "import static a.b.C.*;"
+func (p *Parser) parseImportDecl() *ImportDecl {
+	start := p.next()
+	decl := &ImportDecl{Static: p.accept(KwStatic)}
+	decl.Name = p.parseQualifiedName("SYN107")
+	if p.at(KindDot) && p.peekAt(1).Type == OpMul {
+		p.next()
+		p.next()
+		decl.OnDemand = true
+	}
+	p.expectSemicolon(newPhrase("phrase.import_decl"))
+	decl.Span = p.spanFrom(start)
+
+	// Un import de tipo nombra el paquete y la clase; uno estático, además, el miembro
+	switch {
+	case decl.Static && !decl.OnDemand && !strings.Contains(decl.Name, "."):
+		p.errorf(start, "SYN004", decl.Name)
+	case !decl.Static && !decl.OnDemand && !strings.Contains(decl.Name, "."):
+		p.errorf(start, "SYN005", decl.Name)
+	}
+	return decl
+}
 
-	if parenStart == -1 {
-		return -1
+// atModuleDecl indica si empieza "module a.b {" u "open module a.b {"
+func (p *Parser) atModuleDecl() bool {
+	i := p.skipAnnotations(p.pos)
+	if i < len(p.tokens) && p.tokens[i].Type == KindIdentifier && p.tokens[i].Value == "open" {
+		i++
 	}
+	return i+1 < len(p.tokens) && p.tokens[i].Type == KindIdentifier && p.tokens[i].Value == "module" &&
+		p.tokens[i+1].Type == KindIdentifier
+}
 
-	// Buscar el paréntesis de cierre correspondiente
-	parenCount := 0
-	for i := parenStart; i < len(p.tokens); i++ {
-		if p.tokens[i].Type == "lparen" {
-			parenCount++
-		} else if p.tokens[i].Type == "rparen" {
-			parenCount--
-			if parenCount == 0 {
-				return i
-			}
+// parseModuleDecl analiza la declaración de un módulo y sus directivas
+func (p *Parser) parseModuleDecl() *ModuleDecl {
+	start := p.peek()
+	decl := &ModuleDecl{}
+	if p.peek().Value == "open" {
+		p.next()
+		decl.Open = true
+	}
+	p.next()
+	decl.Name = p.parseQualifiedName("SYN108")
+	open := p.expect(KindLBrace, "SYN006", decl.Name)
+	for !p.at(KindRBrace) {
+		if p.atEnd() {
+			p.errorf(open, "SYN007")
+			decl.Span = p.spanFrom(start)
+			return decl
 		}
+		p.recover(func() {
+			decl.Directives = append(decl.Directives, p.parseModuleDirective())
+		})
 	}
-
-	return -1
+	p.next()
+	decl.Span = p.spanFrom(start)
+	return decl
 }
 
-// Función auxiliar para encontrar el final de una declaración de variable
-func (p *Parser) findVariableDeclarationEnd(start int) int {
-	// Buscar hasta encontrar el final de la declaración
-	for i := start + 1; i < len(p.tokens); i++ {
-		// Si encontramos un semicolon, el final es el token anterior
-		if p.tokens[i].Type == "semicolon" {
-			return i - 1
+// parseModuleDirective analiza una directiva requires, exports, opens, uses o provides
+func (p *Parser) parseModuleDirective() *ModuleDirective {
+	start := p.peek()
+	if start.Type != KindIdentifier {
+		p.fail(start, "SYN008", describeToken(start))
+	}
+	directive := &ModuleDirective{Kind: start.Value}
+	switch start.Value {
+	case "requires":
+		p.next()
+		// "transitive" también puede ser el nombre del módulo: requires transitive;
+		for (p.at(KwStatic) || p.peek().Value == "transitive") && p.peekAt(1).Type == KindIdentifier {
+			directive.Modifiers.Keywords = append(directive.Modifiers.Keywords, p.next().Value)
 		}
-		// Si encontramos una nueva línea o un token que indica nueva declaración
-		if p.tokens[i].Type == "keyword" || p.tokens[i].Value == "{" || p.tokens[i].Value == "}" {
-			return i - 1
+		directive.Name = p.parseQualifiedName("SYN109")
+	case "exports", "opens":
+		p.next()
+		directive.Name = p.parseQualifiedName("SYN106", start.Value)
+		if p.at(KindIdentifier) && p.peek().Value == "to" {
+			p.next()
+			directive.Targets = p.parseQualifiedNameList("SYN110")
 		}
+	case "uses":
+		p.next()
+		directive.Name = p.parseQualifiedName("SYN111", "uses")
+	case "provides":
+		p.next()
+		directive.Name = p.parseQualifiedName("SYN111", "provides")
+		if !p.at(KindIdentifier) || p.peek().Value != "with" {
+			p.fail(p.peek(), "SYN009", directive.Name)
+		}
+		p.next()
+		directive.Targets = p.parseQualifiedNameList("SYN112")
+	default:
+		p.fail(start, "SYN010", start.Value)
+	}
+	p.expectSemicolon(newPhrase("phrase.directive", directive.Kind))
+	directive.Span = p.spanFrom(start)
+	return directive
+}
+
+// parseQualifiedNameList analiza "a.b, c.d"; code es el error si falta un nombre
+func (p *Parser) parseQualifiedNameList(code string) []string {
+	names := []string{p.parseQualifiedName(code)}
+	for p.accept(KindComma) {
+		names = append(names, p.parseQualifiedName(code))
 	}
-	// Si llegamos al final sin encontrar semicolon
-	if len(p.tokens) > start+1 {
-		return len(p.tokens) - 1
-	}
-	return -1
+	return names
 }
 
-// Función auxiliar para encontrar el final de una asignación
-func (p *Parser) findAssignmentEnd(start int) int {
-	// Buscar el = y luego el valor
-	equalPos := -1
-	for i := start; i < len(p.tokens) && i < start+5; i++ {
-		if p.tokens[i].Value == "=" {
-			equalPos = i
-			break
-		}
+// parseQualifiedName analiza "a.b.c"; code es el error si falta el nombre
+func (p *Parser) parseQualifiedName(code string, args ...interface{}) string {
+	name := p.expect(KindIdentifier, code, args...).Value
+	for p.at(KindDot) && p.peekAt(1).Type == KindIdentifier {
+		p.next()
+		name += "." + p.next().Value
 	}
+	return name
+}
 
-	if equalPos == -1 {
-		return -1
+// primitiveTypes palabras reservadas que nombran tipos primitivos
+var primitiveTypes = map[TokenKind]bool{
+	KwBoolean: true, KwByte: true, KwChar: true, KwShort: true,
+	KwInt: true, KwLong: true, KwFloat: true, KwDouble: true,
+}
+
+// parseType analiza un tipo: primitivo o nombre calificado con sus
+// argumentos genéricos, y sus dimensiones
+func (p *Parser) parseType() *TypeNode {
+	node := p.parseTypeNode()
+	if node.Diamond {
+		p.errorf(p.prev(), "SYN011")
 	}
+	return node
+}
 
-	// Buscar el final de la asignación
-	for i := equalPos + 1; i < len(p.tokens); i++ {
-		// Si encontramos un semicolon, el final es el token anterior
-		if p.tokens[i].Type == "semicolon" {
-			return i - 1
+// parseTypeNode analiza un tipo admitiendo el operador diamante, que solo es
+// válido después de new
+func (p *Parser) parseTypeNode() *TypeNode {
+	start := p.peek()
+	node := &TypeNode{Annotations: p.parseAnnotations()}
+	switch {
+	case primitiveTypes[p.peek().Type]:
+		node.Name = p.next().Value
+	case p.at(KindIdentifier):
+		node.Name = p.next().Value
+		node.Args, node.Diamond = p.parseTypeArgs()
+		// En Outer<A>.Inner<B> se conservan los argumentos del último nombre
+		for p.at(KindDot) && p.peekAt(1).Type == KindIdentifier {
+			p.next()
+			node.Name += "." + p.next().Value
+			node.Args, node.Diamond = p.parseTypeArgs()
 		}
-		// Si encontramos tokens que indican nueva declaración
-		if p.tokens[i].Type == "keyword" || p.tokens[i].Value == "{" || p.tokens[i].Value == "}" {
-			return i - 1
+		node.TypeVar = p.isTypeVar(node.Name)
+	default:
+		p.fail(p.peek(), "SYN012", describeToken(p.peek()))
+	}
+	node.Dims, node.DimAnnotations = p.parseDims()
+	node.Span = p.spanFrom(start)
+	return node
+}
+
+// parseTypeArgs analiza los argumentos genéricos "<A, B<C>, ? extends D>", si
+// los hay; diamond indica "<>"
+func (p *Parser) parseTypeArgs() (args []*TypeNode, diamond bool) {
+	if !p.at(OpLess) {
+		return nil, false
+	}
+	p.next()
+	if p.closeTypeArgs() {
+		return nil, true
+	}
+	for {
+		args = append(args, p.parseTypeArg())
+		if !p.accept(KindComma) {
+			break
 		}
 	}
-	
-	// Si llegamos al final sin encontrar semicolon
-	if len(p.tokens) > equalPos+1 {
-		return len(p.tokens) - 1
+	if !p.closeTypeArgs() {
+		p.fail(p.peek(), "SYN013", describeToken(p.peek()))
 	}
-	return -1
+	return args, false
 }
 
-func (p *Parser) validateStatements() {
-	for i := 0; i < len(p.tokens); i++ {
-		if p.tokens[i].Value == "for" {
-			p.validateForLoop(i)
-		}
-		if p.tokens[i].Value == "println" || p.tokens[i].Value == "print" {
-			p.validatePrintStatement(i)
+// parseTypeArg analiza un argumento genérico: un tipo por referencia o un comodín
+func (p *Parser) parseTypeArg() *TypeNode {
+	start := p.peek()
+	if p.accept(OpQuestion) {
+		node := &TypeNode{Name: "?"}
+		switch {
+		case p.accept(KwExtends):
+			node.Bound = p.parseType()
+		case p.accept(KwSuper):
+			node.Bound = p.parseType()
+			node.Super = true
 		}
+		node.Span = p.spanFrom(start)
+		return node
+	}
+	node := p.parseType()
+	if primitiveTypes[start.Type] && node.Dims == 0 {
+		p.errorf(start, "SYN014", node.Name, boxedTypes[node.Name])
 	}
+	return node
 }
 
-func (p *Parser) validateForLoop(start int) {
-	if start+1 >= len(p.tokens) || p.tokens[start+1].Type != "lparen" {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta '(' después de 'for'", p.tokens[start].Line))
-		return
-	}
+// closeTypeArgs consume el '>' que cierra una lista de argumentos genéricos.
+// Si el lexer lo unió a otros ('>>' o '>>>' en List<List<String>>), parte el
+// token y deja el resto para cerrar las listas exteriores.
+func (p *Parser) closeTypeArgs() bool {
+	if p.accept(OpGreater) {
+		return true
+	}
+	first, rest, ok := splitGreater(p.peek())
+	if !ok || p.atEnd() {
+		return false
+	}
+	p.tokens[p.pos] = rest
+	p.tokens = append(p.tokens[:p.pos], append([]Token{first}, p.tokens[p.pos:]...)...)
+	p.pos++
+	return true
+}
 
-	// Buscar el paréntesis de cierre
-	parenCount := 0
-	endParen := -1
-	for i := start + 1; i < len(p.tokens); i++ {
-		if p.tokens[i].Type == "lparen" {
-			parenCount++
-		} else if p.tokens[i].Type == "rparen" {
-			parenCount--
-			if parenCount == 0 {
-				endParen = i
-				break
+// parseTypeParams analiza "<T, U extends Comparable<U> & Serializable>" y
+// pone sus nombres en ámbito; el llamador los retira con restoreTypeVars
+func (p *Parser) parseTypeParams() []*TypeParam {
+	if !p.at(OpLess) {
+		return nil
+	}
+	open := p.next()
+	var params []*TypeParam
+	for {
+		start := p.peek()
+		annotations := p.parseAnnotations()
+		name := p.expect(KindIdentifier, "SYN015")
+		for _, other := range params {
+			if other.Name == name.Value {
+				p.errorf(name, "SYN016", name.Value)
 			}
 		}
+		param := &TypeParam{Annotations: annotations, Name: name.Value}
+		// Los límites pueden referirse al propio parámetro: T extends Comparable<T>
+		p.typeVars = append(p.typeVars, name.Value)
+		if p.accept(KwExtends) {
+			param.Bounds = append(param.Bounds, p.parseType())
+			for p.accept(OpBitAnd) {
+				param.Bounds = append(param.Bounds, p.parseType())
+			}
+		}
+		param.Span = p.spanFrom(start)
+		params = append(params, param)
+		if !p.accept(KindComma) {
+			break
+		}
 	}
-
-	if endParen == -1 {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta ')' en el for", p.tokens[start].Line))
-		return
-	}
-
-	// Validar contenido del for
-	p.validateForContent(start+2, endParen)
-
-	// Validar que hay llave de apertura después del for
-	if endParen+1 >= len(p.tokens) || p.tokens[endParen+1].Type != "lbrace" {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta '{' después del for", p.tokens[endParen].Line))
+	if !p.closeTypeArgs() {
+		p.fail(open, "SYN017")
 	}
+	return params
 }
 
-func (p *Parser) validateForContent(start, end int) {
-	semicolonCount := 0
-	semicolonPos := []int{}
+// restoreTypeVars retira del ámbito los parámetros de tipo declarados
+// después de que hubiera n
+func (p *Parser) restoreTypeVars(n int) {
+	p.typeVars = p.typeVars[:n]
+}
 
-	// Contar punto y coma
-	for i := start; i < end; i++ {
-		if p.tokens[i].Type == "semicolon" {
-			semicolonCount++
-			semicolonPos = append(semicolonPos, i)
+// isTypeVar indica si name es un parámetro de tipo en ámbito
+func (p *Parser) isTypeVar(name string) bool {
+	for i := len(p.typeVars) - 1; i >= 0; i-- {
+		if p.typeVars[i] == name {
+			return true
 		}
 	}
+	return false
+}
 
-	if semicolonCount != 2 {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: For debe tener exactamente 2 ';'", p.tokens[start].Line))
-		return
+// parseResultType analiza el tipo de retorno de un método, que puede ser void
+func (p *Parser) parseResultType() *TypeNode {
+	if p.at(KwVoid) {
+		token := p.next()
+		return &TypeNode{Span: tokenSpan(token), Name: "void"}
 	}
+	return p.parseType()
+}
 
-	// Validar inicialización
-	p.validateForInit(start, semicolonPos[0])
-
-	// Validar condición
-	p.validateForCondition(semicolonPos[0]+1, semicolonPos[1])
+// parseDims analiza los pares "[]" que siguen a un tipo o a un nombre, con
+// las anotaciones de uso de tipo de cada uno: "String @NonNull []". Devuelve
+// las anotaciones por dimensión, o nil si ninguna lleva.
+func (p *Parser) parseDims() (int, [][]*Annotation) {
+	dims := 0
+	var annotations [][]*Annotation
+	annotated := false
+	for p.atDim(p.pos) {
+		list := p.parseAnnotations()
+		annotated = annotated || len(list) > 0
+		annotations = append(annotations, list)
+		p.next()
+		p.next()
+		dims++
+	}
+	if !annotated {
+		return dims, nil
+	}
+	return dims, annotations
+}
 
-	// Validar incremento
-	p.validateForIncrement(semicolonPos[1]+1, end)
+// atDim indica si en i empieza un par "[]", quizá precedido de anotaciones
+func (p *Parser) atDim(i int) bool {
+	i = p.skipAnnotations(i)
+	return i+1 < len(p.tokens) && p.tokens[i].Type == KindLBracket && p.tokens[i+1].Type == KindRBracket
 }
 
-func (p *Parser) validateForInit(start, end int) {
-	if start >= end {
-		p.errors = append(p.errors, "Error: Inicialización del for vacía")
-		return
+// skipType devuelve la posición siguiente al tipo que empieza en i, sin
+// construir nodos, para decidir entre declaración y expresión
+func (p *Parser) skipType(i int) (int, bool) {
+	i = p.skipAnnotations(i)
+	if i >= len(p.tokens) {
+		return i, false
+	}
+	switch {
+	case primitiveTypes[p.tokens[i].Type]:
+		i++
+	case p.tokens[i].Type == KindIdentifier:
+		i++
+		var ok bool
+		if i, ok = p.skipTypeArgs(i); !ok {
+			return i, false
+		}
+		for i+1 < len(p.tokens) && p.tokens[i].Type == KindDot && p.tokens[i+1].Type == KindIdentifier {
+			if i, ok = p.skipTypeArgs(i + 2); !ok {
+				return i, false
+			}
+		}
+	default:
+		return i, false
+	}
+	for p.atDim(i) {
+		i = p.skipAnnotations(i) + 2
 	}
+	return i, true
+}
 
-	// Caso 1: Declaración completa (int i = valor)
-	if end-start >= 4 && p.tokens[start].Type == "keyword" && 
-	   (p.tokens[start].Value == "int" || p.tokens[start].Value == "char") {
-		
-		if p.tokens[start+1].Type != "identifier" {
-			p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Se esperaba identificador después del tipo", p.tokens[start+1].Line))
-			return
+// skipTypeArgs salta los argumentos genéricos que empiecen en i, si los hay.
+// Un '>>' o '>>>' cierra varias listas a la vez.
+func (p *Parser) skipTypeArgs(i int) (int, bool) {
+	if i >= len(p.tokens) || p.tokens[i].Type != OpLess {
+		return i, true
+	}
+	depth := 0
+	for ; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case OpLess:
+			depth++
+		case OpGreater:
+			depth--
+		case OpShiftRight:
+			depth -= 2
+		case OpUnsignedShiftRight:
+			depth -= 3
+		case KindIdentifier, KindDot, KindComma, OpQuestion, KwExtends, KwSuper, OpBitAnd,
+			KindLBracket, KindRBracket:
+			continue
+		case KindAt:
+			// Anotación de uso de tipo: "List<@NonNull String>"
+			next := p.skipAnnotations(i)
+			if next == i {
+				return i, false
+			}
+			i = next - 1
+			continue
+		default:
+			if !primitiveTypes[p.tokens[i].Type] {
+				return i, false
+			}
+			continue
+		}
+		if depth == 0 {
+			return i + 1, true
 		}
+		if depth < 0 {
+			// Un '>>' que cierra más listas de las abiertas no es un tipo
+			return i, false
+		}
+	}
+	return i, false
+}
 
-		if p.tokens[start+2].Value != "=" {
-			p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Se esperaba '=' en la asignación", p.tokens[start+2].Line))
-			return
+// recover ejecuta parse y, si abandona por un error, sincroniza para
+// continuar con la construcción siguiente. Devuelve un ErrorNode con los
+// tokens descartados, o nil si no hubo error.
+func (p *Parser) recover(parse func()) (bad *ErrorNode) {
+	start := p.pos
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseBailout); !ok {
+				panic(r)
+			}
+			p.synchronize(start)
+			if start < len(p.tokens) {
+				bad = &ErrorNode{Span: p.spanFrom(p.tokens[start])}
+			}
 		}
+	}()
+	parse()
+	return nil
+}
 
-		// Validar valor inicial según el tipo
-		if p.tokens[start].Value == "int" {
-			if p.tokens[start+3].Type != "number" {
-				p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Se esperaba número para variable int", p.tokens[start+3].Line))
+// recoverHeader ejecuta parse, que analiza la cabecera de una declaración.
+// Si abandona por un error, descarta tokens hasta la '{' del cuerpo para
+// analizarlo igualmente; si no hay cuerpo, abandona la declaración entera.
+func (p *Parser) recoverHeader(parse func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseBailout); !ok {
+				panic(r)
+			}
+			for !p.atEnd() && !p.at(KindLBrace) && !p.at(KindSemicolon) && !p.at(KindRBrace) {
+				p.next()
 			}
-		} else if p.tokens[start].Value == "char" {
-			if p.tokens[start+3].Type != "char" {
-				p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Se esperaba char literal para variable char", p.tokens[start+3].Line))
+			if !p.at(KindLBrace) {
+				panic(parseBailout{})
 			}
 		}
-		return
-	}
+	}()
+	parse()
+}
 
-	// Caso 2: Solo identificador (variable ya declarada)
-	if end-start == 1 && p.tokens[start].Type == "identifier" {
-		// Es válido, solo debe ser un identificador
-		return
-	}
+// recoverForHeader ejecuta parse, que analiza el resto de la cabecera de un
+// for. Si abandona por un error, descarta tokens hasta el ')' que la cierra o
+// la '{' del cuerpo, de modo que el for conserva lo ya analizado y su cuerpo.
+func (p *Parser) recoverForHeader(parse func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(parseBailout); !ok {
+				panic(r)
+			}
+			for depth := 0; !p.atEnd() && !p.at(KindLBrace) && !p.at(KindRBrace); p.next() {
+				if p.at(KindLParen) {
+					depth++
+				} else if p.at(KindRParen) {
+					if depth == 0 {
+						p.next()
+						return
+					}
+					depth--
+				}
+			}
+			if !p.at(KindLBrace) {
+				panic(parseBailout{})
+			}
+		}
+	}()
+	parse()
+}
 
-	// Caso 3: Asignación a variable existente (i = valor)
-	if end-start >= 3 && p.tokens[start].Type == "identifier" && p.tokens[start+1].Value == "=" {
-		// Validar que hay un valor después del =
-		if p.tokens[start+2].Type != "number" && p.tokens[start+2].Type != "char" && p.tokens[start+2].Type != "identifier" {
-			p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Valor inválido en asignación", p.tokens[start+2].Line))
+// synchronize descarta tokens hasta un punto seguro: después del ';' que
+// termina la sentencia con error, antes de una llave o antes de lo que
+// empieza otra sentencia o declaración
+func (p *Parser) synchronize(start int) {
+	if p.pos == start && !p.atEnd() {
+		p.next()
+	}
+	for !p.atEnd() {
+		switch token := p.peek(); {
+		case token.Type == KindSemicolon:
+			p.next()
+			return
+		case token.Type == KindRBrace, token.Type == KindLBrace:
+			return
+		case syncKeywords[token.Type] && p.prev().Type != KindDot:
+			// "Main.class" no empieza una declaración
+			return
+		case primitiveTypes[token.Type] && token.Line > p.prev().EndLine:
+			// Un tipo primitivo al principio de una línea empieza una declaración
+			return
 		}
-		return
+		p.next()
 	}
+}
 
-	// Si no coincide con ningún patrón válido
-	p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Inicialización del for inválida", p.tokens[start].Line))
+// peek devuelve el token actual o un token vacío al final del código
+func (p *Parser) peek() Token {
+	return p.peekAt(0)
 }
 
-func (p *Parser) validateForCondition(start, end int) {
-	if start >= end {
-		p.errors = append(p.errors, "Error: Condición del for vacía")
-		return
+// peekAt devuelve el token n posiciones más adelante
+func (p *Parser) peekAt(n int) Token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
 	}
+	return p.endToken()
+}
 
-	if end-start < 3 {
-		p.errors = append(p.errors, "Error: Condición del for incompleta")
-		return
+// endToken token vacío situado al final del último token
+func (p *Parser) endToken() Token {
+	if len(p.tokens) == 0 {
+		return Token{Type: KindUnknown, Line: 1, Col: 1, EndLine: 1, EndCol: 1}
+	}
+	last := p.tokens[len(p.tokens)-1]
+	return Token{
+		Type: KindUnknown, Line: last.EndLine, Col: last.EndCol,
+		EndLine: last.EndLine, EndCol: last.EndCol,
+		Offset: last.EndOffset, EndOffset: last.EndOffset,
+		Offset16: last.EndOffset16, EndOffset16: last.EndOffset16,
 	}
+}
 
-	if p.tokens[start].Type != "identifier" {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Se esperaba variable en condición", p.tokens[start].Line))
+// prev devuelve el último token consumido
+func (p *Parser) prev() Token {
+	if p.pos == 0 {
+		return p.peek()
 	}
+	return p.tokens[p.pos-1]
+}
 
-	validOperators := []string{"<", "<=", ">", ">=", "==", "!="}
-	isValidOp := false
-	for _, op := range validOperators {
-		if p.tokens[start+1].Value == op {
-			isValidOp = true
-			break
-		}
+func (p *Parser) atEnd() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *Parser) at(kind TokenKind) bool {
+	return !p.atEnd() && p.tokens[p.pos].Type == kind
+}
+
+// next consume el token actual
+func (p *Parser) next() Token {
+	token := p.peek()
+	if !p.atEnd() {
+		p.pos++
 	}
+	return token
+}
 
-	if !isValidOp {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Operador inválido en condición", p.tokens[start+1].Line))
+// accept consume el token actual si es del tipo indicado
+func (p *Parser) accept(kind TokenKind) bool {
+	if p.at(kind) {
+		p.pos++
+		return true
 	}
+	return false
+}
 
-	if p.tokens[start+2].Type != "number" && p.tokens[start+2].Type != "char" && p.tokens[start+2].Type != "identifier" {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Valor inválido en condición", p.tokens[start+2].Line))
+// expect consume un token del tipo indicado o abandona con el error code. Sin
+// code se informa del token esperado y del encontrado.
+func (p *Parser) expect(kind TokenKind, code string, args ...interface{}) Token {
+	if !p.at(kind) {
+		if code == "" {
+			p.fail(p.peek(), "SYN103", describeKind(kind), describeToken(p.peek()))
+		}
+		p.fail(p.peek(), code, args...)
 	}
+	return p.next()
 }
 
-func (p *Parser) validateForIncrement(start, end int) {
-	if start >= end {
-		p.errors = append(p.errors, "Error: Incremento del for vacío")
+// expectSemicolon exige el ';' que termina una sentencia. El error se sitúa
+// en el último token de la sentencia, que es donde falta el ';'. Si lo que
+// sigue empieza en otra línea o es el comienzo claro de otra sentencia, la
+// sentencia se da por terminada y el análisis continúa sin descartar nada.
+func (p *Parser) expectSemicolon(what phrase) {
+	if p.accept(KindSemicolon) {
 		return
 	}
-
-	if end-start < 2 {
-		p.errors = append(p.errors, "Error: Incremento del for incompleto")
+	next := p.peek()
+	if !p.atEnd() && (next.Line > p.prev().EndLine && next.Type != KindDot ||
+		syncKeywords[next.Type] || primitiveTypes[next.Type]) {
+		p.errorf(p.prev(), "SYN018", what)
 		return
 	}
+	p.fail(p.prev(), "SYN018", what)
+}
 
-	if p.tokens[start].Type != "identifier" {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Se esperaba variable en incremento", p.tokens[start].Line))
+// expectCloseParen exige el ')' que cierra la cabecera de una sentencia. Si
+// en su lugar empieza el cuerpo, se reporta y se analiza el cuerpo igualmente.
+func (p *Parser) expectCloseParen(code string, args ...interface{}) {
+	if p.accept(KindRParen) {
 		return
 	}
-
-	// Verificar que no haya operadores múltiples como c+++
-	if end-start > 2 {
-		// Verificar si hay múltiples operadores seguidos
-		for i := start + 1; i < end-1; i++ {
-			if p.tokens[i].Type == "operator" && p.tokens[i+1].Type == "operator" {
-				// Casos válidos: ++ o --
-				if (p.tokens[i].Value == "+" && p.tokens[i+1].Value == "+") ||
-				   (p.tokens[i].Value == "-" && p.tokens[i+1].Value == "-") {
-					continue
-				}
-				// Casos inválidos como c+++
-				p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Operadores múltiples inválidos en incremento", p.tokens[i].Line))
-				return
-			}
-		}
+	if p.at(KindLBrace) {
+		p.errorf(p.peek(), code, args...)
+		return
 	}
+	p.fail(p.peek(), code, args...)
+}
 
-	validIncrements := []string{"++", "--", "+=", "-="}
-	isValidIncrement := false
-	for _, inc := range validIncrements {
-		if p.tokens[start+1].Value == inc {
-			isValidIncrement = true
-			break
-		}
+// spanFrom devuelve la posición desde start hasta el último token consumido
+func (p *Parser) spanFrom(start Token) Span {
+	if p.pos == 0 || p.prev().Offset < start.Offset {
+		return tokenSpan(start)
 	}
+	return spanBetween(start, p.prev())
+}
 
-	if !isValidIncrement {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Operador de incremento inválido", p.tokens[start+1].Line))
+// errorf reporta el error sintáctico code situado en token, con el mensaje
+// del catálogo formateado con args
+func (p *Parser) errorf(token Token, code string, args ...interface{}) {
+	current := token.Offset >= p.peek().Offset
+	if p.pos == p.lastErrorPos && !(p.lastErrorBefore && current) || p.truncated {
 		return
 	}
-
-	// Verificar que no haya tokens adicionales después del incremento válido
-	if (p.tokens[start+1].Value == "++" || p.tokens[start+1].Value == "--") && end-start > 2 {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Operadores adicionales después de %s", p.tokens[start+1].Line, p.tokens[start+1].Value))
+	p.lastErrorPos = p.pos
+	p.lastErrorBefore = !current
+	p.syntaxErrors++
+	if p.syntaxErrors > maxParseErrors {
+		p.truncated = true
+		p.diagnostics = append(p.diagnostics, newDiagnostic("SYN104", SeverityError, tokenSpan(token)))
 		return
 	}
+	p.diagnostics = append(p.diagnostics, newDiagnostic(code, SeverityError, tokenSpan(token), args...))
+}
 
-	// Si es += o -=, debe haber un valor después
-	if (p.tokens[start+1].Value == "+=" || p.tokens[start+1].Value == "-=") && end-start < 3 {
-		p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Falta valor después de %s", p.tokens[start+1].Line, p.tokens[start+1].Value))
-	}
+// fail reporta un error y abandona la construcción en curso
+func (p *Parser) fail(token Token, code string, args ...interface{}) {
+	p.errorf(token, code, args...)
+	panic(parseBailout{})
 }
 
-func (p *Parser) validatePrintStatement(printPos int) {
-	// Buscar el paréntesis de apertura
-	parenStart := -1
-	for i := printPos + 1; i < len(p.tokens) && i < printPos + 3; i++ {
-		if p.tokens[i].Type == "lparen" {
-			parenStart = i
-			break
+// kindTexts texto de cada operador, separador y palabra reservada
+var kindTexts = func() map[TokenKind]string {
+	texts := make(map[TokenKind]string)
+	for _, table := range []map[string]TokenKind{operators, separators, reservedWords, literalKeywords} {
+		for text, kind := range table {
+			texts[kind] = text
 		}
 	}
+	return texts
+}()
 
-	if parenStart == -1 {
-		return // No es un error crítico, puede ser válido
+// describeKind describe un tipo de token para los mensajes de error
+func describeKind(kind TokenKind) phrase {
+	if text, ok := kindTexts[kind]; ok {
+		return quoted(text)
 	}
-
-	// Buscar el paréntesis de cierre
-	parenCount := 0
-	parenEnd := -1
-	for i := parenStart; i < len(p.tokens); i++ {
-		if p.tokens[i].Type == "lparen" {
-			parenCount++
-		} else if p.tokens[i].Type == "rparen" {
-			parenCount--
-			if parenCount == 0 {
-				parenEnd = i
-				break
-			}
-		}
-	}
-
-	if parenEnd == -1 {
-		return // Error ya manejado por validateBrackets
+	switch kind {
+	case KindIdentifier:
+		return newPhrase("phrase.identifier")
+	case KindString:
+		return newPhrase("phrase.string")
 	}
-
-	// Validar el contenido dentro del println
-	p.validatePrintContent(parenStart + 1, parenEnd)
+	return verbatim(kind.String())
 }
 
-func (p *Parser) validatePrintContent(start, end int) {
-	for i := start; i < end-1; i++ {
-		// Buscar patrones problemáticos en concatenación
-		if p.tokens[i].Type == "string" && i+1 < end {
-			// Verificar concatenación después de string
-			if p.tokens[i+1].Value == "+" && i+2 < end {
-				// Caso válido: "texto" + variable
-				if p.tokens[i+2].Type == "identifier" {
-					continue
-				}
-				// Caso inválido: "texto" ++ variable
-				if p.tokens[i+1].Value == "+" && i+2 < end && p.tokens[i+2].Value == "+" {
-					p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Uso incorrecto de '++' en concatenación, use solo '+'", p.tokens[i+1].Line))
-				}
-			}
-		}
-
-		// Verificar el patrón específico: " ++ variable"
-		if p.tokens[i].Value == "+" && i+1 < end && p.tokens[i+1].Value == "+" && i+2 < end && p.tokens[i+2].Type == "identifier" {
-			p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Uso incorrecto de '++' en concatenación, use solo '+'", p.tokens[i].Line))
-		}
-
-		// También verificar si hay ++ usado como concatenación en cualquier contexto
-		if p.tokens[i].Value == "++" && 
-		   ((i > start && (p.tokens[i-1].Type == "string" || p.tokens[i-1].Type == "identifier")) ||
-		    (i+1 < end && (p.tokens[i+1].Type == "string" || p.tokens[i+1].Type == "identifier"))) {
-			p.errors = append(p.errors, fmt.Sprintf("Error línea %d: Uso incorrecto de '++' para concatenación, use '+' para concatenar", p.tokens[i].Line))
-		}
+// describeToken describe el token encontrado para los mensajes de error
+func describeToken(token Token) phrase {
+	if token.Value == "" && token.Type == KindUnknown {
+		return newPhrase("phrase.end_of_code")
 	}
-}
\ No newline at end of file
+	return quoted(token.Value)
+}
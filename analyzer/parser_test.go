@@ -0,0 +1,244 @@
+// analyzer/parser_test.go
+package analyzer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// syntaxCodes analiza el código y devuelve los códigos de sus diagnósticos
+// léxicos y sintácticos
+func syntaxCodes(code string) []string {
+	_, diagnostics := Parse(Lex(code))
+	codes := make([]string, len(diagnostics))
+	for i, d := range diagnostics {
+		codes[i] = d.Code
+	}
+	return codes
+}
+
+func TestParseErrorCap(t *testing.T) {
+	code := "class A { void m() {\n" + strings.Repeat("# ", 14) + "\nint x = ;\n} }\n"
+	want := strings.Repeat("LEX001,", maxParseErrors) + "LEX013,SYN091"
+	if got := strings.Join(syntaxCodes(code), ","); got != want {
+		t.Errorf("códigos = %s, se esperaban %s", got, want)
+	}
+}
+
+func TestParseRecovery(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "'}' sobrante tras un ';' que falta",
+			code: "int x = 5\n}\n",
+			want: "SYN018,SYN001",
+		},
+		{
+			name: "cabecera del for sin ';'",
+			code: "class A { void f() { for (int i = 0; i < 10 i++) { int y = i; } } }",
+			want: "SYN057",
+		},
+		{
+			name: "cabecera del for sin ';' y cuerpo sin llaves",
+			code: "class A { void f() { for (int i = 0; i < 10 i++) f(); } }",
+			want: "SYN057",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(syntaxCodes(tt.code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseForHeaderRecoveryKeepsLoop(t *testing.T) {
+	unit, _ := ParseFile(Lex("class A { void f() { for (int i = 0; i < 10 i++) { int y = i; } } }"))
+	var loop *ForStmt
+	Inspect(unit, func(n Node) bool {
+		if f, ok := n.(*ForStmt); ok {
+			loop = f
+		}
+		return true
+	})
+	if loop == nil || len(loop.Init) != 1 || loop.Cond == nil || loop.Body == nil {
+		t.Fatalf("el for no conserva la inicialización, la condición y el cuerpo: %+v", loop)
+	}
+}
+
+func TestParseTypeUseAnnotations(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"dimensión de un tipo referencia", "class A { String @Info [] arr; }"},
+		{"dimensión de un tipo primitivo", "class A { void m() { int @Info [] a = new int[1]; } }"},
+		{"varias dimensiones", "class A { int @Info [] @Info [] grid; }"},
+		{"dimensión de un parámetro", "class A { void m(String @Info [] args) {} }"},
+		{"dimensión tras el nombre", "class A { void m() { int c @Info [] = null; } }"},
+		{"parámetro de tipo de un método", "class A { <@Info T> void g() {} }"},
+		{"parámetro de tipo de una clase", "class A<@Info K, @Info V> {}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := syntaxCodes(tt.code); len(got) > 0 {
+				t.Errorf("errores sintácticos: %v", got)
+			}
+		})
+	}
+}
+
+func TestParseQualifiedNew(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		want string
+	}{
+		{"sobre una variable", "outer.new Inner()", ""},
+		{"sobre this", "this.new Inner()", ""},
+		{"sobre una creación, con cuerpo", "new Outer().new Inner() { }", ""},
+		{"creación de array", "outer.new int[3]", "SYN113"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := "class Outer { class Inner { } void m(Outer outer) { Object x = " + tt.expr + "; } }"
+			if got := strings.Join(syntaxCodes(code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseArrayClassLiterals(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"int[][].class", ""},
+		{"String[].class", ""},
+		{"java.lang.String[][].class", ""},
+		{"a[0]", ""},
+		{"String[]", "SYN092"},
+		{"String[].length", "SYN092"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			code := "class A { void m(int[] a) { Object x = " + tt.expr + "; } }"
+			if got := strings.Join(syntaxCodes(code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseControlFlow(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"sentencias válidas", "if (x > 0) x--; else if (x < 0) x++; else { } while (x > 0) x--; do x++; while (x < 3); for (;;) break; ; assert x > 0 : \"positivo\"; synchronized (this) { } throw new RuntimeException();", ""},
+		{"etiquetas", "outer: for (int i = 0; i < 3; i++) { inner: while (true) { continue outer; } }", ""},
+		{"falta ')' en el while", "while (x > 0 { x--; }", "SYN053"},
+		{"falta '(' en el if", "if x > 0 { }", "SYN052"},
+		{"falta ';' tras el do-while", "do { x--; } while (x > 0)", "SYN018"},
+		{"falta ')' en el for", "for (int i = 0; i < 3; i++ { }", "SYN058"},
+		{"default sin ':'", "switch (x) { case 1: break; default }", "SYN074"},
+		{"else sin if", "else { }", "SYN049"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := "class A { void m(int x) { " + tt.body + " } }"
+			if got := strings.Join(syntaxCodes(code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTry(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"catch y finally", "try { } catch (Exception e) { } finally { }", ""},
+		{"multi-catch", "try { } catch (IllegalArgumentException | IllegalStateException e) { }", ""},
+		{"recursos", "try (java.io.Reader r = null; java.io.Reader s = r) { }", ""},
+		{"try sin catch ni finally", "try { x++; }", "SYN062"},
+		{"catch sin nombre", "try { } catch (Exception) { }", "SYN068"},
+		{"catch sin cuerpo", "try { } catch (Exception e) finally { }", "SYN070"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := "class A { void m(int x) { " + tt.body + " } }"
+			if got := strings.Join(syntaxCodes(code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseModuleDeclarations(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"paquete e imports", "package com.example; import java.util.*; import static java.lang.Math.max; class A { }", ""},
+		{"module-info", "module com.example.app { requires java.base; requires transitive com.example.core; exports com.example.api; exports com.example.internal to com.example.test; opens com.example.model; uses com.example.Service; provides com.example.Service with com.example.Impl; }", ""},
+		{"requires sin módulo", "module com.example { requires; }", "SYN109"},
+		{"provides sin with", "module com.example { provides com.example.Service; }", "SYN009"},
+		{"import sin ';'", "import java.util.List class A { }", "SYN018"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(syntaxCodes(tt.code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLambdasAndMethodRefs(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"formas válidas", "Runnable r = () -> { }; Object f = s -> s; Object g = (a, b) -> a; Object h = (int a, int b) -> a + b; Object k = String::length; Object n = int[]::new; Object t = this::m;", ""},
+		{"tipos mezclados", "Object f = (int a, b) -> a;", "SYN083"},
+		{"parámetro sin nombre", "Object f = (int) -> 1;", "SYN084"},
+		{"falta ')' en los parámetros", "Object f = (a -> a;", "SYN094"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := "class A { void m(int x) { " + tt.body + " } }"
+			if got := strings.Join(syntaxCodes(code), ","); got != tt.want {
+				t.Errorf("códigos = %s, se esperaban %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDiagnosticsInSourceOrder(t *testing.T) {
+	// El error léxico de la línea 3 se detecta antes que el sintáctico de la 2
+	code := "class A {\n  int x = 1\n  int y = 2; #\n}"
+	if got := strings.Join(syntaxCodes(code), ","); got != "SYN018,LEX001" {
+		t.Errorf("códigos = %s, se esperaban SYN018,LEX001", got)
+	}
+}
+
+func TestParseMessages(t *testing.T) {
+	tokens := Lex("class A { void m() { int x = 1 } }")
+	ok, messages := ParseMessages(tokens)
+	_, diagnostics := Parse(tokens)
+	if ok || !reflect.DeepEqual(messages, Messages(diagnostics)) {
+		t.Errorf("ParseMessages = %v, %q; se esperaba false, %q", ok, messages, Messages(diagnostics))
+	}
+}
@@ -0,0 +1,356 @@
+// analyzer/rules.go
+package analyzer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Rule comprobación semántica configurable. Cada regla agrupa los códigos de
+// diagnóstico que produce, con la gravedad Severity salvo que la
+// configuración la cambie; sus opciones son texto y las valida check.
+type Rule struct {
+	ID          string
+	Codes       []string
+	Severity    Severity
+	Description string
+	Options     map[string]string `json:",omitempty"`
+
+	check func(option, value string) error
+}
+
+// rules reglas con nombre. Los códigos SEM que no aparecen aquí también se
+// pueden configurar usando el propio código como identificador.
+var rules = []Rule{
+	{
+		ID:          "char-range",
+		Codes:       []string{"SEM123"},
+		Severity:    SeverityError,
+		Description: "Los literales char deben estar en el rango permitido (regla del curso)",
+		Options:     map[string]string{"range": "a-z"},
+		check: func(option, value string) error {
+			_, err := parseCharRanges(value)
+			return err
+		},
+	},
+	{
+		ID:          "for-variable",
+		Codes:       []string{"SEM121", "SEM122"},
+		Severity:    SeverityError,
+		Description: "La condición y el incremento del for deben usar la variable del for (regla del curso)",
+	},
+	{
+		ID:          "literal-range",
+		Codes:       []string{"SEM124", "SEM125"},
+		Severity:    SeverityError,
+		Description: "Los literales numéricos deben caber en su tipo",
+	},
+	{
+		ID:          "switch-fallthrough",
+		Codes:       []string{"SEM075"},
+		Severity:    SeverityWarning,
+		Description: "Un case no debe continuar en el siguiente sin 'break'",
+	},
+	{
+		ID:          "package-name",
+		Codes:       []string{"SEM084"},
+		Severity:    SeverityWarning,
+		Description: "Los nombres de paquete se escriben en minúsculas",
+	},
+	{
+		ID:          "duplicate-import",
+		Codes:       []string{"SEM085"},
+		Severity:    SeverityWarning,
+		Description: "Un import no debe repetirse",
+	},
+	{
+		ID:          "redundant-import",
+		Codes:       []string{"SEM086"},
+		Severity:    SeverityWarning,
+		Description: "No hace falta importar java.lang",
+	},
+	{
+		ID:          "unused-import",
+		Codes:       []string{"SEM089"},
+		Severity:    SeverityWarning,
+		Description: "Todo import debe usarse",
+	},
+}
+
+// Rules devuelve las reglas con nombre y su configuración por defecto
+func Rules() []Rule {
+	return rules
+}
+
+// findRule busca una regla por identificador
+func findRule(id string) *Rule {
+	for i := range rules {
+		if rules[i].ID == id {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// ruleOfCode devuelve la regla que produce el código, o nil
+func ruleOfCode(code string) *Rule {
+	for i := range rules {
+		for _, c := range rules[i].Codes {
+			if c == code {
+				return &rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+// RuleSettings configuración de una regla; los campos vacíos mantienen el
+// valor por defecto. En JSON admite también las formas cortas "off", "on",
+// true, false o el nombre de una gravedad ("warning"). Sus claves van en
+// minúsculas, como los demás campos de la petición y del perfil rules.json.
+type RuleSettings struct {
+	Enabled  *bool             `json:"enabled,omitempty"`
+	Severity Severity          `json:"severity,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+}
+
+// UnmarshalJSON acepta la forma completa y las formas cortas
+func (s *RuleSettings) UnmarshalJSON(data []byte) error {
+	var enabled bool
+	if err := json.Unmarshal(data, &enabled); err == nil {
+		*s = RuleSettings{Enabled: &enabled}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		switch text {
+		case "off":
+			enabled = false
+			*s = RuleSettings{Enabled: &enabled}
+		case "on":
+			enabled = true
+			*s = RuleSettings{Enabled: &enabled}
+		default:
+			*s = RuleSettings{Severity: Severity(text)}
+		}
+		return nil
+	}
+	type plain RuleSettings
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+// RuleConfig configuración de las reglas por identificador de regla o por
+// código de diagnóstico, como {"char-range": "off", "SEM089": "hint"}
+type RuleConfig map[string]RuleSettings
+
+// LoadRuleConfig lee una configuración de reglas de un fichero JSON
+func LoadRuleConfig(path string) (RuleConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config RuleConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return config, nil
+}
+
+// RuleSet configuración de reglas ya validada. Un RuleSet nil equivale a la
+// configuración por defecto.
+type RuleSet struct {
+	settings map[string]RuleSettings
+}
+
+// NewRuleSet valida y combina las configuraciones; cada una se aplica sobre
+// las anteriores, como la del perfil del servidor y la de la petición
+func NewRuleSet(configs ...RuleConfig) (*RuleSet, error) {
+	set := &RuleSet{settings: map[string]RuleSettings{}}
+	for _, config := range configs {
+		keys := make([]string, 0, len(config))
+		for key := range config {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			settings := config[key]
+			if err := validateRuleSettings(key, settings); err != nil {
+				return nil, err
+			}
+			merged := set.settings[key]
+			if settings.Enabled != nil {
+				merged.Enabled = settings.Enabled
+			}
+			if settings.Severity != "" {
+				merged.Severity = settings.Severity
+			}
+			for option, value := range settings.Options {
+				if merged.Options == nil {
+					merged.Options = map[string]string{}
+				}
+				merged.Options[option] = value
+			}
+			set.settings[key] = merged
+		}
+	}
+	return set, nil
+}
+
+// validateRuleSettings comprueba que key sea una regla o un código semántico
+// y que la gravedad y las opciones existan
+func validateRuleSettings(key string, settings RuleSettings) error {
+	rule := findRule(key)
+	if rule == nil && !isSemanticCode(key) {
+		return fmt.Errorf("regla desconocida '%s'", key)
+	}
+	switch settings.Severity {
+	case "", SeverityError, SeverityWarning, SeverityInfo, SeverityHint:
+	default:
+		return fmt.Errorf("gravedad no válida '%s' en la regla '%s'", settings.Severity, key)
+	}
+	for option, value := range settings.Options {
+		if rule == nil && ruleOfCode(key) != nil {
+			return fmt.Errorf("el código '%s' no tiene opciones, configure la regla '%s'", key, ruleOfCode(key).ID)
+		}
+		if rule == nil {
+			return fmt.Errorf("el código '%s' no tiene opciones", key)
+		}
+		if _, ok := rule.Options[option]; !ok {
+			return fmt.Errorf("la regla '%s' no tiene la opción '%s'", key, option)
+		}
+		if rule.check != nil {
+			if err := rule.check(option, value); err != nil {
+				return fmt.Errorf("opción '%s' de la regla '%s': %v", option, key, err)
+			}
+		}
+	}
+	return nil
+}
+
+// isSemanticCode indica si el código es un diagnóstico semántico del catálogo
+func isSemanticCode(code string) bool {
+	if !strings.HasPrefix(code, "SEM") || strings.Contains(code, ".") {
+		return false
+	}
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+	_, ok := catalogs.messages[DefaultLocale][code]
+	return ok
+}
+
+// ruleID identificador de la regla que produce el código, o el propio código
+func ruleID(code string) string {
+	if rule := ruleOfCode(code); rule != nil {
+		return rule.ID
+	}
+	return code
+}
+
+// active indica si el código se reporta: manda la configuración del código y
+// después la de su regla
+func (s *RuleSet) active(code string) bool {
+	if s == nil {
+		return true
+	}
+	if settings, ok := s.settings[code]; ok && settings.Enabled != nil {
+		return *settings.Enabled
+	}
+	if settings, ok := s.settings[ruleID(code)]; ok && settings.Enabled != nil {
+		return *settings.Enabled
+	}
+	return true
+}
+
+// enabled indica si alguno de los códigos de la regla se reporta, para no
+// ejecutar las comprobaciones desactivadas
+func (s *RuleSet) enabled(id string) bool {
+	for _, code := range findRule(id).Codes {
+		if s.active(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// option devuelve el valor de una opción de la regla
+func (s *RuleSet) option(id, name string) string {
+	if s != nil {
+		if value, ok := s.settings[id].Options[name]; ok {
+			return value
+		}
+	}
+	return findRule(id).Options[name]
+}
+
+// severity devuelve la gravedad configurada para el código, o la del
+// diagnóstico si no se cambió
+func (s *RuleSet) severity(code string, current Severity) Severity {
+	if s == nil {
+		return current
+	}
+	if settings := s.settings[code]; settings.Severity != "" {
+		return settings.Severity
+	}
+	if settings := s.settings[ruleID(code)]; settings.Severity != "" {
+		return settings.Severity
+	}
+	return current
+}
+
+// apply quita los diagnósticos de las reglas desactivadas, les da la gravedad
+// configurada o la de su regla y anota la regla que los produce
+func (s *RuleSet) apply(diagnostics []Diagnostic) []Diagnostic {
+	result := make([]Diagnostic, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		if !s.active(d.Code) {
+			continue
+		}
+		if rule := ruleOfCode(d.Code); rule != nil {
+			d.Rule = rule.ID
+			d.Severity = rule.Severity
+		}
+		d.Severity = s.severity(d.Code, d.Severity)
+		result = append(result, d)
+	}
+	return result
+}
+
+// charRange rango de caracteres permitidos, con los extremos incluidos
+type charRange struct {
+	lo, hi rune
+}
+
+// parseCharRanges interpreta rangos como "a-z" o "a-zA-Z0-9_": pares
+// separados por '-' o caracteres sueltos
+func parseCharRanges(text string) ([]charRange, error) {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return nil, fmt.Errorf("el rango está vacío")
+	}
+	var ranges []charRange
+	for i := 0; i < len(runes); i++ {
+		r := charRange{runes[i], runes[i]}
+		if i+2 < len(runes) && runes[i+1] == '-' {
+			r.hi = runes[i+2]
+			i += 2
+		}
+		if r.lo > r.hi {
+			return nil, fmt.Errorf("rango '%c-%c' invertido", r.lo, r.hi)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// inCharRanges indica si el carácter está en alguno de los rangos
+func inCharRanges(c rune, ranges []charRange) bool {
+	for _, r := range ranges {
+		if c >= r.lo && c <= r.hi {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// analyzer/rules_test.go
+package analyzer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// ruleTestCode programa con un char fuera de a-z y un for que mezcla variables
+const ruleTestCode = `class A {
+  void m() {
+    char c = 'A';
+    int j = 0;
+    for (int i = 0; j < 10; j++) { }
+  }
+}`
+
+func TestRulesOnBothAnalyzers(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RuleConfig
+		want   map[string]Severity
+	}{
+		{
+			name: "por defecto",
+			want: map[string]Severity{"SEM123": SeverityError, "SEM121": SeverityError, "SEM122": SeverityError},
+		},
+		{
+			name:   "regla desactivada",
+			config: RuleConfig{"for-variable": {Enabled: new(bool)}},
+			want:   map[string]Severity{"SEM123": SeverityError},
+		},
+		{
+			name:   "gravedad de la regla cambiada",
+			config: RuleConfig{"char-range": {Severity: SeverityWarning}, "SEM122": {Severity: SeverityHint}},
+			want:   map[string]Severity{"SEM123": SeverityWarning, "SEM121": SeverityError, "SEM122": SeverityHint},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := NewRuleSet(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, semantic := AnalyzeSemanticsWithRules(Lex(ruleTestCode), rules)
+			_, optimized := NewEnhancedSemanticAnalyzer().AnalyzeOptimizedWithRules(Lex(ruleTestCode), rules)
+			for name, diagnostics := range map[string][]Diagnostic{"semántico": semantic, "optimizado": optimized} {
+				got := map[string]Severity{}
+				for _, d := range diagnostics {
+					got[d.Code] = d.Severity
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("análisis %s = %v, se esperaba %v", name, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// TestRuleJSON comprueba que las reglas de /rules se serializan con los
+// nombres de campo, como los diagnósticos, y que la configuración de una
+// regla sigue leyéndose con claves en minúsculas
+func TestRuleJSON(t *testing.T) {
+	data, err := json.Marshal(findRule("char-range"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"ID", "Codes", "Severity", "Description", "Options"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("falta el campo %s en %s", key, data)
+		}
+	}
+
+	var config RuleConfig
+	if err := json.Unmarshal([]byte(`{"char-range": {"severity": "warning", "options": {"range": "a-z"}}}`), &config); err != nil {
+		t.Fatal(err)
+	}
+	settings := config["char-range"]
+	if settings.Severity != SeverityWarning || settings.Options["range"] != "a-z" {
+		t.Errorf("configuración = %+v", settings)
+	}
+}
@@ -0,0 +1,180 @@
+// analyzer/scanner.go
+package analyzer
+
+import (
+	"bufio"
+	"io"
+	"iter"
+)
+
+// defaultBufferSize caracteres leídos por bloque cuando no se indica otro tamaño
+const defaultBufferSize = 4096
+
+// tokenLookahead caracteres que el lexer puede examinar más allá del final de
+// un token para decidir dónde termina (p. ej. ">>>=" o el inicio de "/*")
+const tokenLookahead = 8
+
+// Scanner analizador léxico incremental: lee el código de un io.Reader y
+// produce los tokens de uno en uno. Solo conserva en memoria el bloque que se
+// está analizando, que crece únicamente si un token no cabe en él.
+type Scanner struct {
+	reader     *bufio.Reader
+	opts       LexerOptions
+	bufferSize int
+
+	buf    []rune  // caracteres leídos
+	widths []uint8 // bytes UTF-8 que ocupa cada carácter de buf en la entrada
+	pos    int     // primer carácter de buf aún sin analizar
+	eof    bool
+	err    error
+
+	line, col        int
+	offset, offset16 int
+
+	// pending tokens leídos por adelantado o devueltos con Unread
+	pending []Token
+}
+
+// NewScanner crea un Scanner sin optimizaciones de memoria
+func NewScanner(r io.Reader) *Scanner {
+	return NewScannerWithOptions(r, LexerOptions{})
+}
+
+// NewScannerWithOptions crea un Scanner con las opciones indicadas
+func NewScannerWithOptions(r io.Reader, opts LexerOptions) *Scanner {
+	size := opts.BufferSize
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+	return &Scanner{
+		reader:     bufio.NewReader(r),
+		opts:       opts,
+		bufferSize: size,
+		line:       1,
+		col:        1,
+	}
+}
+
+// Next devuelve el siguiente token. ok es false al llegar al final de la
+// entrada o si la lectura falla; en ese caso Err indica el motivo.
+func (s *Scanner) Next() (token Token, ok bool) {
+	if n := len(s.pending); n > 0 {
+		token = s.pending[n-1]
+		s.pending = s.pending[:n-1]
+		return token, true
+	}
+	return s.scan()
+}
+
+// Peek devuelve el siguiente token sin consumirlo
+func (s *Scanner) Peek() (Token, bool) {
+	token, ok := s.Next()
+	if ok {
+		s.Unread(token)
+	}
+	return token, ok
+}
+
+// Unread devuelve un token al Scanner para que Next lo entregue de nuevo.
+// Los tokens se devuelven en orden inverso al que se leyeron.
+func (s *Scanner) Unread(token Token) {
+	s.pending = append(s.pending, token)
+}
+
+// Err devuelve el error de lectura que detuvo el análisis, si lo hubo
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Tokens devuelve un iterador sobre los tokens restantes
+func (s *Scanner) Tokens() iter.Seq[Token] {
+	return func(yield func(Token) bool) {
+		for {
+			token, ok := s.Next()
+			if !ok || !yield(token) {
+				return
+			}
+		}
+	}
+}
+
+// scan reconoce el siguiente token del código
+func (s *Scanner) scan() (Token, bool) {
+	for {
+		s.skipWhitespace()
+		if s.pos >= len(s.buf) {
+			if s.eof {
+				return Token{}, false
+			}
+			s.fill()
+			continue
+		}
+
+		// Analizar sobre copias de la posición: si el token puede continuar
+		// en el siguiente bloque se vuelve a reconocer tras leerlo
+		i, line, col := s.pos, s.line, s.col
+		token := lexToken(s.buf, &i, &line, &col)
+		if !s.eof && i+tokenLookahead >= len(s.buf) {
+			s.fill()
+			continue
+		}
+
+		token.Offset, token.Offset16 = s.offset, s.offset16
+		s.advance(i)
+		token.EndOffset, token.EndOffset16 = s.offset, s.offset16
+		s.line, s.col = line, col
+		token.EndLine, token.EndCol = line, col
+		if token.Diagnostic != nil {
+			token.Diagnostic.Span = tokenSpan(*token)
+		}
+
+		if s.opts.Strings != nil && token.Type != KindComment {
+			token.Value = s.opts.Strings.InternString(token.Value)
+		}
+		return *token, true
+	}
+}
+
+// skipWhitespace consume los espacios y saltos de línea disponibles en buf
+func (s *Scanner) skipWhitespace() {
+	for s.pos < len(s.buf) {
+		switch c := s.buf[s.pos]; {
+		case c == '\n':
+			s.line++
+			s.col = 1
+		case isWhitespace(c):
+			s.col++
+		default:
+			return
+		}
+		s.advance(s.pos + 1)
+	}
+}
+
+// advance consume los caracteres de buf hasta end actualizando los desplazamientos
+func (s *Scanner) advance(end int) {
+	for ; s.pos < end; s.pos++ {
+		s.offset += int(s.widths[s.pos])
+		s.offset16 += utf16Len(s.buf[s.pos])
+	}
+}
+
+// fill descarta los caracteres ya analizados y lee el siguiente bloque
+func (s *Scanner) fill() {
+	n := copy(s.buf, s.buf[s.pos:])
+	copy(s.widths, s.widths[s.pos:])
+	s.buf, s.widths, s.pos = s.buf[:n], s.widths[:n], 0
+
+	for read := 0; read < s.bufferSize; read++ {
+		r, size, err := s.reader.ReadRune()
+		if err != nil {
+			if err != io.EOF {
+				s.err = err
+			}
+			s.eof = true
+			return
+		}
+		s.buf = append(s.buf, r)
+		s.widths = append(s.widths, uint8(size))
+	}
+}
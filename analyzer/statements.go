@@ -0,0 +1,567 @@
+// analyzer/statements.go
+package analyzer
+
+// parseBlock analiza un bloque "{ ... }"
+func (p *Parser) parseBlock() *Block {
+	open := p.expect(KindLBrace, "")
+	block := &Block{}
+	for !p.at(KindRBrace) {
+		if p.atEnd() {
+			p.errorf(open, "SYN007")
+			block.Span = p.spanFrom(open)
+			return block
+		}
+		bad := p.recover(func() {
+			block.Stmts = append(block.Stmts, p.parseBlockStatement())
+		})
+		if bad != nil {
+			block.Stmts = append(block.Stmts, bad)
+		}
+	}
+	p.next()
+	block.Span = p.spanFrom(open)
+	return block
+}
+
+// parseBlockStatement analiza una declaración de variables locales o una sentencia
+func (p *Parser) parseBlockStatement() Stmt {
+	if p.atLocalTypeDeclStart() {
+		start := p.peek()
+		decl := p.parseTypeDecl(start, p.parseModifiers())
+		return &LocalClassStmt{Span: decl.Span, Decl: decl}
+	}
+	if p.atLocalVarDeclStart() && !p.atYield() {
+		start := p.peek()
+		decl := p.parseLocalVarDecl()
+		p.expectSemicolon(newPhrase("phrase.var_decl"))
+		decl.Span = p.spanFrom(start)
+		return decl
+	}
+	return p.parseStatement()
+}
+
+// atLocalVarDeclStart indica si empieza una declaración de variables locales:
+// un modificador, o un tipo seguido de un identificador
+func (p *Parser) atLocalVarDeclStart() bool {
+	i := p.skipAnnotations(p.pos)
+	if i < len(p.tokens) && p.tokens[i].Type == KwFinal {
+		return true
+	}
+	i, ok := p.skipType(i)
+	return ok && i < len(p.tokens) && p.tokens[i].Type == KindIdentifier
+}
+
+// parseLocalVarDecl analiza "final int a = 1, b" sin el ';' final
+func (p *Parser) parseLocalVarDecl() *LocalVarDecl {
+	start := p.peek()
+	decl := &LocalVarDecl{Modifiers: p.parseModifiers()}
+	decl.Type = p.parseType()
+	decl.Vars = p.parseVarDeclarators()
+	decl.Span = p.spanFrom(start)
+	return decl
+}
+
+// parseStatement analiza una sentencia
+func (p *Parser) parseStatement() Stmt {
+	start := p.peek()
+	switch start.Type {
+	case KindLBrace:
+		return p.parseBlock()
+	case KindIdentifier:
+		if p.atYield() {
+			p.next()
+			stmt := &YieldStmt{Value: p.parseExpression()}
+			p.expectSemicolon(quoted("yield"))
+			stmt.Span = p.spanFrom(start)
+			return stmt
+		}
+		if p.peekAt(1).Type == OpColon {
+			p.next()
+			p.next()
+			stmt := &LabeledStmt{Label: start.Value, Body: p.parseStatement()}
+			stmt.Span = p.spanFrom(start)
+			return stmt
+		}
+	case KindSemicolon:
+		p.next()
+		return &EmptyStmt{Span: tokenSpan(start)}
+	case KwIf:
+		return p.parseIfStmt()
+	case KwWhile:
+		return p.parseWhileStmt()
+	case KwDo:
+		return p.parseDoStmt()
+	case KwFor:
+		return p.parseForStmt()
+	case KwSwitch:
+		selector, cases := p.parseSwitch(false)
+		return &SwitchStmt{Span: p.spanFrom(start), Selector: selector, Cases: cases}
+	case KwReturn:
+		p.next()
+		stmt := &ReturnStmt{}
+		if !p.at(KindSemicolon) {
+			stmt.Value = p.parseExpression()
+		}
+		p.expectSemicolon(quoted("return"))
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case KwBreak:
+		p.next()
+		stmt := &BreakStmt{}
+		if p.at(KindIdentifier) {
+			stmt.Label = p.next().Value
+		}
+		p.expectSemicolon(quoted("break"))
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case KwContinue:
+		p.next()
+		stmt := &ContinueStmt{}
+		if p.at(KindIdentifier) {
+			stmt.Label = p.next().Value
+		}
+		p.expectSemicolon(quoted("continue"))
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case KwThrow:
+		p.next()
+		if p.at(KindSemicolon) {
+			p.fail(p.peek(), "SYN045")
+		}
+		stmt := &ThrowStmt{Value: p.parseExpression()}
+		p.expectSemicolon(quoted("throw"))
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case KwAssert:
+		p.next()
+		stmt := &AssertStmt{Cond: p.parseExpression()}
+		if p.accept(OpColon) {
+			stmt.Message = p.parseExpression()
+		}
+		p.expectSemicolon(quoted("assert"))
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case KwSynchronized:
+		p.next()
+		p.expect(KindLParen, "SYN046")
+		stmt := &SynchronizedStmt{Lock: p.parseExpression()}
+		p.expect(KindRParen, "SYN047")
+		if !p.at(KindLBrace) {
+			p.fail(p.peek(), "SYN048")
+		}
+		stmt.Body = p.parseBlock()
+		stmt.Span = p.spanFrom(start)
+		return stmt
+	case KwTry:
+		return p.parseTryStmt()
+	case KwElse:
+		p.fail(start, "SYN049")
+	case KwCatch, KwFinally:
+		p.fail(start, "SYN050", start.Value)
+	}
+
+	x := p.parseExpression()
+	if !isStatementExpression(x) {
+		p.fail(start, "SYN051")
+	}
+	p.expectSemicolon(describeStatement(x))
+	return &ExprStmt{Span: p.spanFrom(start), X: x}
+}
+
+// parseCondition analiza "( expresión )" de if, while y do-while
+func (p *Parser) parseCondition(keyword string) Expr {
+	p.expect(KindLParen, "SYN052", keyword)
+	cond := p.parseExpression()
+	p.expectCloseParen("SYN053", keyword)
+	return cond
+}
+
+func (p *Parser) parseIfStmt() Stmt {
+	start := p.next()
+	stmt := &IfStmt{Cond: p.parseCondition("if")}
+	stmt.Then = p.parseStatement()
+	if p.accept(KwElse) {
+		stmt.Else = p.parseStatement()
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *Parser) parseWhileStmt() Stmt {
+	start := p.next()
+	stmt := &WhileStmt{Cond: p.parseCondition("while")}
+	stmt.Body = p.parseStatement()
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+func (p *Parser) parseDoStmt() Stmt {
+	start := p.next()
+	stmt := &DoStmt{Body: p.parseStatement()}
+	p.expect(KwWhile, "SYN054")
+	stmt.Cond = p.parseCondition("do-while")
+	p.expectSemicolon(newPhrase("phrase.do_while"))
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+// parseForStmt analiza "for (init; cond; update) cuerpo" y el for-each
+func (p *Parser) parseForStmt() Stmt {
+	start := p.next()
+	p.expect(KindLParen, "SYN055")
+	if p.atForEachHeader() {
+		return p.parseForEachStmt(start)
+	}
+	stmt := &ForStmt{}
+
+	if !p.at(KindSemicolon) {
+		if p.atLocalVarDeclStart() {
+			stmt.Init = []Stmt{p.parseLocalVarDecl()}
+		} else {
+			for _, x := range p.parseStatementExpressionList() {
+				stmt.Init = append(stmt.Init, &ExprStmt{Span: x.Range(), X: x})
+			}
+		}
+	}
+	p.recoverForHeader(func() {
+		p.expect(KindSemicolon, "SYN056")
+		if !p.at(KindSemicolon) {
+			stmt.Cond = p.parseExpression()
+		}
+		p.expect(KindSemicolon, "SYN057")
+		if !p.at(KindRParen) {
+			stmt.Update = p.parseStatementExpressionList()
+		}
+		p.expectCloseParen("SYN058")
+	})
+
+	stmt.Body = p.parseStatement()
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+// atForEachHeader indica si la cabecera del for es "T x :"
+func (p *Parser) atForEachHeader() bool {
+	i := p.skipAnnotations(p.pos)
+	for i < len(p.tokens) && modifierKeywords[p.tokens[i].Type] {
+		i = p.skipAnnotations(i + 1)
+	}
+	i, ok := p.skipType(i)
+	return ok && i+1 < len(p.tokens) && p.tokens[i].Type == KindIdentifier && p.tokens[i+1].Type == OpColon
+}
+
+// parseForEachStmt analiza "T x : expresión) cuerpo"; el '(' ya se consumió
+func (p *Parser) parseForEachStmt(start Token) Stmt {
+	stmt := &ForEachStmt{Modifiers: p.parseModifiers()}
+	stmt.VarType = p.parseType()
+	stmt.Name = p.next().Value
+	p.next() // ':'
+	if p.at(KindRParen) {
+		p.fail(p.peek(), "SYN059")
+	}
+	stmt.Iterable = p.parseExpression()
+	p.expectCloseParen("SYN058")
+	stmt.Body = p.parseStatement()
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+// parseTryStmt analiza try, try-with-resources, sus catch y su finally
+func (p *Parser) parseTryStmt() Stmt {
+	start := p.next()
+	stmt := &TryStmt{}
+	if p.accept(KindLParen) {
+		stmt.Resources = p.parseResources()
+	}
+	if !p.at(KindLBrace) {
+		p.fail(p.peek(), "SYN060")
+	}
+	stmt.Body = p.parseBlock()
+
+	for p.at(KwCatch) {
+		stmt.Catches = append(stmt.Catches, p.parseCatchClause())
+	}
+	if p.accept(KwFinally) {
+		if !p.at(KindLBrace) {
+			p.fail(p.peek(), "SYN061")
+		}
+		stmt.Finally = p.parseBlock()
+	}
+	if len(stmt.Catches) == 0 && stmt.Finally == nil && stmt.Resources == nil {
+		p.errorf(start, "SYN062")
+	}
+	stmt.Span = p.spanFrom(start)
+	return stmt
+}
+
+// parseResources analiza los recursos de try-with-resources hasta el ')'
+// que los cierra: declaraciones inicializadas o variables existentes
+// separadas por ';', con un ';' final opcional
+func (p *Parser) parseResources() []Node {
+	resources := []Node{}
+	for !p.accept(KindRParen) {
+		start := p.peek()
+		if p.atLocalVarDeclStart() {
+			decl := &LocalVarDecl{Modifiers: p.parseModifiers(), Type: p.parseType()}
+			name := p.expect(KindIdentifier, "SYN063")
+			v := &VarDeclarator{Name: name.Value}
+			p.expect(OpAssign, "SYN064", name.Value)
+			v.Init = p.parseExpression()
+			v.Span = p.spanFrom(name)
+			decl.Vars = []*VarDeclarator{v}
+			decl.Span = p.spanFrom(start)
+			resources = append(resources, decl)
+		} else {
+			x := p.parseExpression()
+			if _, ok := x.(*Ident); !ok {
+				if _, ok := x.(*FieldAccess); !ok {
+					p.errorf(start, "SYN065")
+				}
+			}
+			resources = append(resources, x)
+		}
+		if !p.accept(KindSemicolon) && !p.at(KindRParen) {
+			p.fail(p.peek(), "SYN066")
+		}
+	}
+	return resources
+}
+
+// parseCatchClause analiza "catch (final A | B e) { ... }"
+func (p *Parser) parseCatchClause() *CatchClause {
+	start := p.next()
+	p.expect(KindLParen, "SYN067")
+	clause := &CatchClause{Modifiers: p.parseModifiers()}
+	clause.Types = []*TypeNode{p.parseType()}
+	for p.accept(OpBitOr) {
+		clause.Types = append(clause.Types, p.parseType())
+	}
+	clause.Name = p.expect(KindIdentifier, "SYN068").Value
+	p.expect(KindRParen, "SYN069")
+	if !p.at(KindLBrace) {
+		p.fail(p.peek(), "SYN070")
+	}
+	clause.Body = p.parseBlock()
+	clause.Span = p.spanFrom(start)
+	return clause
+}
+
+// atYield indica si empieza una sentencia yield. "yield" es una palabra
+// clave contextual: "yield = 3;" o "yield.f()" siguen siendo expresiones.
+func (p *Parser) atYield() bool {
+	if !p.at(KindIdentifier) || p.peek().Value != "yield" {
+		return false
+	}
+	next := p.peekAt(1).Type
+	switch {
+	case assignmentOperators[next], next == KindDot, next == KindLBracket, next == OpColon,
+		next == OpIncrement, next == OpDecrement, next == KindSemicolon:
+		return false
+	}
+	return true
+}
+
+// parseSwitch analiza "switch (selector) { ramas }" como sentencia o como
+// expresión. Todas las ramas deben usar la misma forma: "case X:" o "case X ->".
+func (p *Parser) parseSwitch(expression bool) (Expr, []*SwitchCase) {
+	p.next()
+	selector := p.parseCondition("switch")
+	open := p.expect(KindLBrace, "SYN071")
+
+	var cases []*SwitchCase
+	for !p.at(KindRBrace) {
+		if p.atEnd() {
+			p.fail(open, "SYN007")
+		}
+		if !p.at(KwCase) && !p.at(KwDefault) {
+			p.errorf(p.peek(), "SYN072", describeToken(p.peek()))
+			p.recover(func() { p.parseBlockStatement() })
+			continue
+		}
+		p.recover(func() {
+			start := p.peek()
+			c := p.parseSwitchCase(expression)
+			if len(cases) > 0 && cases[0].Arrow != c.Arrow {
+				p.errorf(start, "SYN073")
+			}
+			cases = append(cases, c)
+		})
+	}
+	p.next()
+	return selector, cases
+}
+
+// parseSwitchCase analiza una rama con sus etiquetas y su cuerpo
+func (p *Parser) parseSwitchCase(expression bool) *SwitchCase {
+	start := p.next()
+	c := &SwitchCase{Default: start.Type == KwDefault}
+	if start.Type == KwCase {
+		p.parseCaseLabels(c)
+	}
+
+	switch {
+	case p.accept(OpArrow):
+		c.Arrow = true
+		c.Body = []Stmt{p.parseArrowBody(expression)}
+	case p.accept(OpColon):
+		for !p.at(KwCase) && !p.at(KwDefault) && !p.at(KindRBrace) && !p.atEnd() {
+			bad := p.recover(func() {
+				c.Body = append(c.Body, p.parseBlockStatement())
+			})
+			if bad != nil {
+				c.Body = append(c.Body, bad)
+			}
+		}
+	default:
+		p.fail(p.peek(), "SYN074", start.Value)
+	}
+	c.Span = p.spanFrom(start)
+	return c
+}
+
+// parseCaseLabels analiza "A, B", "null, default" o "T t when cond"
+func (p *Parser) parseCaseLabels(c *SwitchCase) {
+	p.caseLabel = true
+	defer func() { p.caseLabel = false }()
+	for {
+		switch {
+		case p.at(KwDefault):
+			p.next()
+			c.Default = true
+		case p.atPattern():
+			if c.Pattern != nil || len(c.Labels) > 0 {
+				p.errorf(p.peek(), "SYN075")
+			}
+			c.Pattern = p.parsePattern()
+		default:
+			// Sin el operador condicional, cuyo ':' terminaría la etiqueta
+			c.Labels = append(c.Labels, p.parseBinary(precOr))
+		}
+		if !p.accept(KindComma) {
+			break
+		}
+	}
+	if c.Pattern != nil && p.at(KindIdentifier) && p.peek().Value == "when" {
+		p.next()
+		c.Guard = p.parseBinary(precOr)
+	}
+}
+
+// atPattern indica si empieza un patrón: "T t" o "T(...)"
+func (p *Parser) atPattern() bool {
+	i, ok := p.skipType(p.pos)
+	if !ok || i >= len(p.tokens) {
+		return false
+	}
+	return p.tokens[i].Type == KindIdentifier || p.tokens[i].Type == KindLParen && !primitiveTypes[p.peek().Type]
+}
+
+// parsePattern analiza un patrón de tipo o de record
+func (p *Parser) parsePattern() *Pattern {
+	start := p.peek()
+	p.accept(KwFinal)
+	pattern := &Pattern{Type: p.parseType()}
+	if p.accept(KindLParen) {
+		pattern.Record = true
+		for !p.at(KindRParen) {
+			pattern.Components = append(pattern.Components, p.parsePattern())
+			if !p.accept(KindComma) {
+				break
+			}
+		}
+		p.expect(KindRParen, "SYN076")
+		if p.at(KindIdentifier) && p.peek().Value != "when" {
+			pattern.Name = p.next().Value
+		}
+	} else {
+		pattern.Name = p.expect(KindIdentifier, "SYN077").Value
+	}
+	pattern.Span = p.spanFrom(start)
+	return pattern
+}
+
+// parseArrowBody analiza lo que sigue a "->": un bloque, un throw o una
+// expresión. En un switch usado como sentencia la expresión debe ser una
+// sentencia válida.
+func (p *Parser) parseArrowBody(expression bool) Stmt {
+	start := p.peek()
+	switch start.Type {
+	case KindLBrace:
+		return p.parseBlock()
+	case KwThrow:
+		return p.parseStatement()
+	}
+	x := p.parseExpression()
+	if !expression && !isStatementExpression(x) {
+		p.errorf(start, "SYN051")
+	}
+	p.expectSemicolon(describeStatement(x))
+	return &ExprStmt{Span: p.spanFrom(start), X: x}
+}
+
+// parseStatementExpressionList analiza expresiones separadas por comas que
+// deben ser sentencias válidas, como en la inicialización y el incremento del for
+func (p *Parser) parseStatementExpressionList() []Expr {
+	var list []Expr
+	for {
+		start := p.peek()
+		x := p.parseExpression()
+		if !isStatementExpression(x) {
+			p.errorf(start, "SYN051")
+		}
+		list = append(list, x)
+		if !p.accept(KindComma) {
+			return list
+		}
+	}
+}
+
+// isStatementExpression indica si la expresión puede usarse como sentencia
+func isStatementExpression(x Expr) bool {
+	switch x := x.(type) {
+	case *AssignExpr, *MethodCall, *NewExpr, *ConstructorCall:
+		return true
+	case *UnaryExpr:
+		return x.Op == OpIncrement || x.Op == OpDecrement
+	}
+	return false
+}
+
+// describeStatement describe una sentencia de expresión para los mensajes
+func describeStatement(x Expr) phrase {
+	switch x := x.(type) {
+	case *AssignExpr:
+		return newPhrase("phrase.assignment")
+	case *MethodCall:
+		return newPhrase("phrase.call", callName(x))
+	case *ConstructorCall:
+		return newPhrase("phrase.call", x.keyword())
+	case *UnaryExpr:
+		if x.Op == OpDecrement {
+			return newPhrase("phrase.decrement")
+		}
+		return newPhrase("phrase.increment")
+	}
+	return newPhrase("phrase.expression")
+}
+
+// callName devuelve el nombre calificado de una llamada, p. ej. "System.out.println"
+func callName(call *MethodCall) string {
+	if name := qualifiedName(call.Target); name != "" {
+		return name + "." + call.Name
+	}
+	return call.Name
+}
+
+// qualifiedName devuelve "a.b.c" si la expresión es una cadena de nombres
+func qualifiedName(x Expr) string {
+	switch x := x.(type) {
+	case *Ident:
+		return x.Name
+	case *FieldAccess:
+		if prefix := qualifiedName(x.X); prefix != "" {
+			return prefix + "." + x.Name
+		}
+	}
+	return ""
+}
@@ -39,19 +39,9 @@ func (sl *StringLibrary) ValidateJavaString(s string) bool {
 }
 
 func (sl *StringLibrary) validateStringContent(s string) bool {
-	// Validar caracteres escapados válidos
-	for i := 0; i < len(s); i++ {
-		if s[i] == '\\' && i+1 < len(s) {
-			next := s[i+1]
-			switch next {
-			case 'n', 't', 'r', '\\', '"', '\'':
-				i++ // saltar el carácter escapado
-			default:
-				return false
-			}
-		}
-	}
-	return true
+	// Validar escapes con las mismas reglas que el lexer
+	_, problem := decodeEscapes(s)
+	return problem.empty()
 }
 
 // GetStringMethods retorna métodos disponibles para String en Java
@@ -0,0 +1,241 @@
+// analyzer/switch_checks.go
+package analyzer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkSwitch analiza un switch usado como sentencia o como expresión y
+// devuelve el tipo del valor que produce la expresión
+func (c *checker) checkSwitch(selector Expr, cases []*SwitchCase, expression bool) string {
+	selectorType := c.typeOf(selector)
+	switch unbox(selectorType) {
+	case "long", "float", "double", "boolean":
+		c.errorf(selector, "SEM073", selectorType)
+		selectorType = typeUnknown
+	}
+
+	var results []string
+	outerFlow := c.flow
+	if expression {
+		// Las ramas de una expresión switch solo pueden salir con yield
+		c.flow = flowContext{returnType: outerFlow.returnType, yields: &results}
+	} else {
+		c.flow.breakable++
+	}
+
+	seen := make(map[string]bool)
+	hasDefault, unconditional, patterns := false, false, false
+	// En la forma "case X:" todas las ramas comparten el ámbito del bloque
+	c.withScope(func() {
+		for i, sc := range cases {
+			if sc.Default {
+				if hasDefault {
+					c.errorf(sc, "SEM074")
+				}
+				hasDefault = true
+			}
+			for _, label := range sc.Labels {
+				c.checkCaseLabel(label, selectorType, seen)
+			}
+
+			check := func() {
+				if sc.Pattern != nil {
+					patterns = true
+					c.checkPattern(sc.Pattern, selectorType)
+					if sc.Guard == nil && coversType(sc.Pattern, selectorType) {
+						unconditional = true
+					}
+				}
+				if sc.Guard != nil {
+					c.checkCondition(sc.Guard, "when")
+				}
+				c.checkCaseBody(sc, expression, &results)
+			}
+			if sc.Arrow || sc.Pattern != nil {
+				c.withScope(check)
+			} else {
+				check()
+			}
+
+			if !sc.Arrow && i < len(cases)-1 && len(sc.Body) > 0 && !completesAbruptly(sc.Body[len(sc.Body)-1]) {
+				c.warnf(sc, "SEM075")
+			}
+		}
+	})
+	c.flow = outerFlow
+
+	if !hasDefault && !unconditional && (expression || patterns) {
+		c.checkExhaustive(selector, selectorType, cases, expression)
+	}
+	if !expression {
+		return typeUnknown
+	}
+	return switchResultType(results)
+}
+
+// checkCaseBody analiza el cuerpo de una rama. En una expresión switch la
+// expresión tras "->" es el valor de la rama.
+func (c *checker) checkCaseBody(sc *SwitchCase, expression bool, results *[]string) {
+	if expression && sc.Arrow && len(sc.Body) == 1 {
+		if stmt, ok := sc.Body[0].(*ExprStmt); ok {
+			*results = append(*results, c.typeOf(stmt.X))
+			return
+		}
+	}
+	c.checkStmts(sc.Body)
+}
+
+// checkCaseLabel comprueba que la constante de un case sea compatible con
+// el selector y que no se repita
+func (c *checker) checkCaseLabel(label Expr, selectorType string, seen map[string]bool) {
+	var typ string
+	if id, ok := label.(*Ident); ok && c.scope.lookup(id.Name) == nil && !isPrimitiveType(unbox(selectorType)) && selectorType != "String" {
+		// Constante de un enum: se nombra sin calificar
+		typ = selectorType
+		if decl := c.lookupType(selectorType); decl != nil && decl.Kind == "enum" {
+			if !hasEnumConstant(decl, id.Name) {
+				c.errorf(id, "SEM078", id.Name, decl.Name)
+			}
+		}
+	} else {
+		typ = c.typeOf(label)
+	}
+
+	if typ == typeNull {
+		if isPrimitiveType(selectorType) {
+			c.errorf(label, "SEM079", selectorType)
+		}
+	} else if !isAssignableType(unbox(selectorType), typ, constantValue(label)) && !isAssignableType(selectorType, typ, constantValue(label)) {
+		c.errorf(label, "SEM080", typ, selectorType)
+	}
+
+	if key, text := caseLabelKey(label); key != "" {
+		if seen[key] {
+			c.errorf(label, "SEM081", text)
+		}
+		seen[key] = true
+	}
+}
+
+// checkPattern declara las variables de un patrón y comprueba que su tipo
+// sea compatible con el selector
+func (c *checker) checkPattern(pattern *Pattern, selectorType string) {
+	typ := typeName(pattern.Type, 0)
+	if isPrimitiveType(selectorType) && !pattern.Record {
+		c.errorf(pattern, "SEM082", selectorType)
+	} else if isKnownReferenceType(typ) && isKnownReferenceType(selectorType) && typ != selectorType {
+		c.errorf(pattern, "SEM083", typ, selectorType)
+	}
+	for _, component := range pattern.Components {
+		c.checkPattern(component, typeUnknown)
+	}
+	if pattern.Name != "" {
+		c.declare(pattern.Name, typ, pattern, nil)
+	}
+}
+
+// checkExhaustive reporta un switch sin default que no cubre todos los
+// valores del selector, nombrando las constantes o los subtipos que faltan
+func (c *checker) checkExhaustive(selector Expr, selectorType string, cases []*SwitchCase, expression bool) {
+	missing, exhaustive := c.uncoveredCases(selectorType, cases)
+	if exhaustive {
+		return
+	}
+	uncovered := newPhrase("phrase.all_values")
+	if len(missing) > 0 {
+		uncovered = newPhrase("phrase.uncovered_cases", "'"+strings.Join(missing, "', '")+"'")
+	}
+	if expression {
+		c.errorf(selector, "SEM076", uncovered)
+	} else {
+		c.errorf(selector, "SEM077", uncovered)
+	}
+}
+
+// coversType indica si un patrón sin condición acepta cualquier valor del selector
+func coversType(pattern *Pattern, selectorType string) bool {
+	if pattern.Record {
+		return false
+	}
+	typ := typeName(pattern.Type, 0)
+	return typ == "Object" || typ == selectorType
+}
+
+// completesAbruptly indica si la sentencia nunca continúa en la siguiente
+func completesAbruptly(stmt Stmt) bool {
+	switch s := stmt.(type) {
+	case *BreakStmt, *ContinueStmt, *ReturnStmt, *ThrowStmt, *YieldStmt:
+		return true
+	case *Block:
+		return len(s.Stmts) > 0 && completesAbruptly(s.Stmts[len(s.Stmts)-1])
+	case *IfStmt:
+		return s.Else != nil && completesAbruptly(s.Then) && completesAbruptly(s.Else)
+	}
+	return false
+}
+
+// caseLabelKey devuelve la clave que identifica una constante de case, para
+// detectar duplicados, y su texto para los mensajes. La clave es "" si no es
+// una constante conocida.
+func caseLabelKey(label Expr) (string, string) {
+	x := unparen(label)
+	if value := constantValue(x); value != nil {
+		// 'a' y 97 son la misma etiqueta
+		key := fmt.Sprint(*value)
+		if lit, ok := x.(*Literal); ok && lit.Raw != "" {
+			return key, lit.Raw
+		}
+		return key, key
+	}
+	switch x := x.(type) {
+	case *Literal:
+		if x.Kind == KindString {
+			return x.Raw, x.Raw
+		}
+		if x.Kind == KwNull {
+			return "null", "null"
+		}
+	case *Ident:
+		return x.Name, x.Name
+	}
+	name := qualifiedName(x)
+	return name, name
+}
+
+// switchResultType calcula el tipo de una expresión switch a partir de los
+// valores de sus ramas
+func switchResultType(results []string) string {
+	if len(results) == 0 {
+		return typeUnknown
+	}
+	result := results[0]
+	for _, typ := range results[1:] {
+		switch {
+		case result == typeUnknown || typ == typeUnknown:
+			return typeUnknown
+		case typ == result:
+		case isNumericType(result) && isNumericType(typ):
+			result = binaryPromotion(result, typ)
+		case isBooleanType(result) && isBooleanType(typ):
+			result = "boolean"
+		case typ == typeNull && !isPrimitiveType(result):
+		case result == typeNull && !isPrimitiveType(typ):
+			result = typ
+		default:
+			return typeUnknown
+		}
+	}
+	return result
+}
+
+// hasEnumConstant indica si el enum declara la constante indicada
+func hasEnumConstant(decl *ClassDecl, name string) bool {
+	for _, constant := range decl.Constants {
+		if constant.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,205 @@
+// analyzer/text_blocks.go
+package analyzer
+
+import (
+	"strings"
+)
+
+// lexTextBlock reconoce un bloque de texto ("""...""") que empieza en runes[*i].
+// Aplica, en este orden, la normalización de saltos de línea, la eliminación
+// de la indentación incidental y el procesamiento de escapes.
+func lexTextBlock(runes []rune, i *int, line *int, col *int) *Token {
+	start := *i
+	startLine := *line
+	startCol := *col
+
+	// Tras la apertura solo puede haber espacios hasta el salto de línea. Si
+	// no es así se sigue hasta el cierre para reportar un único error.
+	pos := start + 3
+	for pos < len(runes) && isTextBlockSpace(runes[pos]) {
+		pos++
+	}
+	openedOK := pos < len(runes) && (runes[pos] == '\n' || runes[pos] == '\r')
+	if !openedOK {
+		pos = start + 3
+	} else {
+		if runes[pos] == '\r' && pos+1 < len(runes) && runes[pos+1] == '\n' {
+			pos++
+		}
+		pos++
+	}
+
+	reader := &unicodeReader{runes: runes, pos: pos}
+	var content strings.Builder
+	closed := false
+	var problem phrase
+
+	for {
+		c, unicodeProblem, ok := reader.next()
+		if !ok {
+			break
+		}
+		if !unicodeProblem.empty() {
+			if problem.empty() {
+				problem = unicodeProblem
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			if closesTextBlock(reader) {
+				closed = true
+			} else {
+				content.WriteRune(c)
+				continue
+			}
+		case '\\':
+			// Los escapes se procesan después de quitar la indentación
+			content.WriteRune(c)
+			if next, _, ok := reader.next(); ok {
+				if next == '\r' {
+					next = '\n'
+					if following, ok := reader.peek(); ok && following == '\n' {
+						reader.pos++
+					}
+				}
+				content.WriteRune(next)
+			}
+			continue
+		case '\r':
+			// Normalizar CRLF y CR a LF
+			if following, ok := reader.peek(); ok && following == '\n' {
+				reader.pos++
+			}
+			content.WriteRune('\n')
+			continue
+		default:
+			content.WriteRune(c)
+			continue
+		}
+		break
+	}
+
+	*i = reader.pos
+	advancePosition(runes[start:*i], line, col)
+	raw := string(runes[start:*i])
+
+	if !openedOK {
+		return lexicalError("LEX011", startLine, startCol, raw)
+	}
+	if !closed {
+		return lexicalError("LEX010", startLine, startCol, raw)
+	}
+	if !problem.empty() {
+		return lexicalError("LEX012", startLine, startCol, raw, problem)
+	}
+
+	value, problem := processTextBlockEscapes(stripIndent(content.String()))
+	if !problem.empty() {
+		return lexicalError("LEX012", startLine, startCol, raw, problem)
+	}
+	return &Token{Type: KindString, Value: value, Line: startLine, Col: startCol, Raw: raw}
+}
+
+// closesTextBlock comprueba si tras un '"' ya leído vienen otros dos; si no,
+// deja el lector donde estaba.
+func closesTextBlock(reader *unicodeReader) bool {
+	pos, backslashes := reader.pos, reader.backslashes
+	for n := 0; n < 2; n++ {
+		c, problem, ok := reader.next()
+		if !ok || !problem.empty() || c != '"' {
+			reader.pos, reader.backslashes = pos, backslashes
+			return false
+		}
+	}
+	return true
+}
+
+// stripIndent elimina la indentación incidental común a las líneas
+// significativas y los espacios finales de cada línea (String.stripIndent).
+func stripIndent(content string) string {
+	lines := strings.Split(content, "\n")
+	last := len(lines) - 1
+
+	// Las líneas en blanco no cuentan, salvo la del delimitador de cierre
+	minIndent := -1
+	for n, text := range lines {
+		if isBlankLine(text) && n != last {
+			continue
+		}
+		indent := 0
+		for _, r := range text {
+			if !isTextBlockSpace(r) {
+				break
+			}
+			indent++
+		}
+		if minIndent == -1 || indent < minIndent {
+			minIndent = indent
+		}
+	}
+
+	for n, text := range lines {
+		if isBlankLine(text) {
+			lines[n] = ""
+			continue
+		}
+		runes := []rune(text)
+		lines[n] = strings.TrimRight(string(runes[minIndent:]), " \t\f")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// processTextBlockEscapes interpreta los escapes de un bloque de texto; además
+// de los habituales admite '\' seguido de salto de línea (continuación).
+func processTextBlockEscapes(content string) (string, phrase) {
+	reader := &unicodeReader{runes: []rune(content), plain: true}
+	var value strings.Builder
+	for {
+		c, _, ok := reader.next()
+		if !ok {
+			return value.String(), phrase{}
+		}
+		if c != '\\' {
+			value.WriteRune(c)
+			continue
+		}
+		if next, ok := reader.peek(); ok && next == '\n' {
+			reader.pos++
+			continue
+		}
+		decoded, problem := readEscape(reader)
+		if !problem.empty() {
+			return "", problem
+		}
+		value.WriteRune(decoded)
+	}
+}
+
+// advancePosition actualiza línea y columna tras consumir el texto dado
+func advancePosition(consumed []rune, line *int, col *int) {
+	for n := 0; n < len(consumed); n++ {
+		switch consumed[n] {
+		case '\r':
+			if n+1 < len(consumed) && consumed[n+1] == '\n' {
+				n++
+			}
+			*line++
+			*col = 1
+		case '\n':
+			*line++
+			*col = 1
+		default:
+			*col++
+		}
+	}
+}
+
+func isTextBlockSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\f'
+}
+
+func isBlankLine(text string) bool {
+	return strings.TrimLeft(text, " \t\f") == ""
+}
@@ -2,74 +2,360 @@
 package analyzer
 
 type Token struct {
-	Type  string
+	Type  TokenKind
 	Value string
 	Line  int
 	Col   int
+	// EndLine y EndCol indican la posición siguiente al último carácter
+	EndLine int
+	EndCol  int
+	// Offset y EndOffset delimitan el token en bytes UTF-8 del código fuente
+	Offset    int
+	EndOffset int
+	// Offset16 y EndOffset16 delimitan el token en unidades UTF-16
+	Offset16    int
+	EndOffset16 int
+	// Raw contiene el texto original de los literales de string y char
+	Raw string `json:",omitempty"`
+	// Doc contiene las etiquetas de los comentarios Javadoc
+	Doc *DocComment `json:",omitempty"`
+	// Diagnostic error léxico de los tokens KindError y KindUnknown
+	Diagnostic *Diagnostic `json:",omitempty"`
 }
 
-var keywords = map[string]bool{
-	"abstract":     true,
-	"String[]":     true,
-	"assert":       true,
-	"boolean":      true,
-	"break":        true,
-	"byte":         true,
-	"case":         true,
-	"catch":        true,
-	"char":         true,
-	"class":        true,
-	"const":        true,
-	"continue":     true,
-	"default":      true,
-	"do":           true,
-	"double":       true,
-	"else":         true,
-	"enum":         true,
-	"extends":      true,
-	"final":        true,
-	"finally":      true,
-	"float":        true,
-	"for":          true,
-	"goto":         true,
-	"if":           true,
-	"implements":   true,
-	"import":       true,
-	"instanceof":   true,
-	"int":          true,
-	"interface":    true,
-	"long":         true,
-	"native":       true,
-	"new":          true,
-	"package":      true,
-	"private":      true,
-	"protected":    true,
-	"public":       true,
-	"return":       true,
-	"short":        true,
-	"static":       true,
-	"strictfp":     true,
-	"super":        true,
-	"switch":       true,
-	"synchronized": true,
-	"this":         true,
-	"throw":        true,
-	"throws":       true,
-	"transient":    true,
-	"try":          true,
-	"void":         true,
-	"volatile":     true,
-	"while":        true,
-	"String":       true,
-	"System":       true,
-	"out":          true,
-	"println":      true,
-	"print":        true,
-	"main":         true,
-	"args":         true,
-	"true":         true,
-	"false":        true,
-	"null":         true,
-	"ejercicio":    true,
-	"equals":       true,
+// Text devuelve el texto del token tal y como aparece en source
+func (t Token) Text(source string) string {
+	if t.Offset < 0 || t.EndOffset > len(source) || t.Offset > t.EndOffset {
+		return ""
+	}
+	return source[t.Offset:t.EndOffset]
+}
+
+// TokenKind identifica el tipo de un token. Los operadores y las palabras
+// reservadas tienen un valor propio cada uno, pero al serializarse se
+// agrupan bajo su categoría ("operator", "keyword") para mantener el JSON.
+type TokenKind int
+
+const (
+	KindUnknown TokenKind = iota
+	KindError
+	KindIdentifier
+	KindNumber
+	KindLong
+	KindFloat
+	KindDouble
+	KindString
+	KindChar
+	KindSemicolon
+	KindLParen
+	KindRParen
+	KindLBrace
+	KindRBrace
+	KindComma
+	KindDot
+	KindComment
+	KindLBracket
+	KindRBracket
+	KindEllipsis
+	KindAt
+	KindDoubleColon
+
+	operatorBegin
+	OpAdd                      // +
+	OpIncrement                // ++
+	OpAddAssign                // +=
+	OpSub                      // -
+	OpDecrement                // --
+	OpSubAssign                // -=
+	OpMul                      // *
+	OpMulAssign                // *=
+	OpDiv                      // /
+	OpDivAssign                // /=
+	OpLess                     // <
+	OpLessEqual                // <=
+	OpGreater                  // >
+	OpGreaterEqual             // >=
+	OpAssign                   // =
+	OpEqual                    // ==
+	OpNot                      // !
+	OpNotEqual                 // !=
+	OpBitAnd                   // &
+	OpAnd                      // &&
+	OpBitOr                    // |
+	OpOr                       // ||
+	OpBitAndAssign             // &=
+	OpBitOrAssign              // |=
+	OpXor                      // ^
+	OpXorAssign                // ^=
+	OpMod                      // %
+	OpModAssign                // %=
+	OpShiftLeft                // <<
+	OpShiftLeftAssign          // <<=
+	OpShiftRight               // >>
+	OpShiftRightAssign         // >>=
+	OpUnsignedShiftRight       // >>>
+	OpUnsignedShiftRightAssign // >>>=
+	OpBitNot                   // ~
+	OpQuestion                 // ?
+	OpColon                    // :
+	OpArrow                    // ->
+	operatorEnd
+
+	keywordBegin
+	KwAbstract
+	KwAssert
+	KwBoolean
+	KwBreak
+	KwByte
+	KwCase
+	KwCatch
+	KwChar
+	KwClass
+	KwConst
+	KwContinue
+	KwDefault
+	KwDo
+	KwDouble
+	KwElse
+	KwEnum
+	KwExtends
+	KwFinal
+	KwFinally
+	KwFloat
+	KwFor
+	KwGoto
+	KwIf
+	KwImplements
+	KwImport
+	KwInstanceof
+	KwInt
+	KwInterface
+	KwLong
+	KwNative
+	KwNew
+	KwPackage
+	KwPrivate
+	KwProtected
+	KwPublic
+	KwReturn
+	KwShort
+	KwStatic
+	KwStrictfp
+	KwSuper
+	KwSwitch
+	KwSynchronized
+	KwThis
+	KwThrow
+	KwThrows
+	KwTransient
+	KwTry
+	KwVoid
+	KwVolatile
+	KwWhile
+	KwUnderscore // _
+	KwTrue
+	KwFalse
+	KwNull
+	keywordEnd
+)
+
+// kindNames contiene el valor expuesto en JSON de cada categoría
+var kindNames = [...]string{
+	KindUnknown:     "unknown",
+	KindError:       "error",
+	KindIdentifier:  "identifier",
+	KindNumber:      "number",
+	KindLong:        "long",
+	KindFloat:       "float",
+	KindDouble:      "double",
+	KindString:      "string",
+	KindChar:        "char",
+	KindSemicolon:   "semicolon",
+	KindLParen:      "lparen",
+	KindRParen:      "rparen",
+	KindLBrace:      "lbrace",
+	KindRBrace:      "rbrace",
+	KindComma:       "comma",
+	KindDot:         "dot",
+	KindComment:     "comment",
+	KindLBracket:    "lbracket",
+	KindRBracket:    "rbracket",
+	KindEllipsis:    "ellipsis",
+	KindAt:          "at",
+	KindDoubleColon: "doublecolon",
+}
+
+// IsOperator indica si el tipo corresponde a un operador
+func (k TokenKind) IsOperator() bool {
+	return k > operatorBegin && k < operatorEnd
+}
+
+// IsKeyword indica si el tipo corresponde a una palabra reservada
+func (k TokenKind) IsKeyword() bool {
+	return k > keywordBegin && k < keywordEnd
+}
+
+// String devuelve el nombre del tipo tal y como aparece en el JSON
+func (k TokenKind) String() string {
+	switch {
+	case k.IsOperator():
+		return "operator"
+	case k.IsKeyword():
+		return "keyword"
+	case k >= 0 && int(k) < len(kindNames) && kindNames[k] != "":
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+// MarshalText serializa el tipo con su nombre de categoría
+func (k TokenKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+var operators = map[string]TokenKind{
+	"+":    OpAdd,
+	"++":   OpIncrement,
+	"+=":   OpAddAssign,
+	"-":    OpSub,
+	"--":   OpDecrement,
+	"-=":   OpSubAssign,
+	"*":    OpMul,
+	"*=":   OpMulAssign,
+	"/":    OpDiv,
+	"/=":   OpDivAssign,
+	"<":    OpLess,
+	"<=":   OpLessEqual,
+	">":    OpGreater,
+	">=":   OpGreaterEqual,
+	"=":    OpAssign,
+	"==":   OpEqual,
+	"!":    OpNot,
+	"!=":   OpNotEqual,
+	"&":    OpBitAnd,
+	"&&":   OpAnd,
+	"|":    OpBitOr,
+	"||":   OpOr,
+	"&=":   OpBitAndAssign,
+	"|=":   OpBitOrAssign,
+	"^":    OpXor,
+	"^=":   OpXorAssign,
+	"%":    OpMod,
+	"%=":   OpModAssign,
+	"<<":   OpShiftLeft,
+	"<<=":  OpShiftLeftAssign,
+	">>":   OpShiftRight,
+	">>=":  OpShiftRightAssign,
+	">>>":  OpUnsignedShiftRight,
+	">>>=": OpUnsignedShiftRightAssign,
+	"~":    OpBitNot,
+	"?":    OpQuestion,
+	":":    OpColon,
+	"->":   OpArrow,
+}
+
+// separators contiene los separadores de Java
+var separators = map[string]TokenKind{
+	"(":   KindLParen,
+	")":   KindRParen,
+	"{":   KindLBrace,
+	"}":   KindRBrace,
+	"[":   KindLBracket,
+	"]":   KindRBracket,
+	";":   KindSemicolon,
+	",":   KindComma,
+	".":   KindDot,
+	"...": KindEllipsis,
+	"@":   KindAt,
+	"::":  KindDoubleColon,
+}
+
+// reservedWords palabras reservadas de Java (JLS §3.9); nunca pueden usarse
+// como identificadores
+var reservedWords = map[string]TokenKind{
+	"abstract":     KwAbstract,
+	"assert":       KwAssert,
+	"boolean":      KwBoolean,
+	"break":        KwBreak,
+	"byte":         KwByte,
+	"case":         KwCase,
+	"catch":        KwCatch,
+	"char":         KwChar,
+	"class":        KwClass,
+	"const":        KwConst,
+	"continue":     KwContinue,
+	"default":      KwDefault,
+	"do":           KwDo,
+	"double":       KwDouble,
+	"else":         KwElse,
+	"enum":         KwEnum,
+	"extends":      KwExtends,
+	"final":        KwFinal,
+	"finally":      KwFinally,
+	"float":        KwFloat,
+	"for":          KwFor,
+	"goto":         KwGoto,
+	"if":           KwIf,
+	"implements":   KwImplements,
+	"import":       KwImport,
+	"instanceof":   KwInstanceof,
+	"int":          KwInt,
+	"interface":    KwInterface,
+	"long":         KwLong,
+	"native":       KwNative,
+	"new":          KwNew,
+	"package":      KwPackage,
+	"private":      KwPrivate,
+	"protected":    KwProtected,
+	"public":       KwPublic,
+	"return":       KwReturn,
+	"short":        KwShort,
+	"static":       KwStatic,
+	"strictfp":     KwStrictfp,
+	"super":        KwSuper,
+	"switch":       KwSwitch,
+	"synchronized": KwSynchronized,
+	"this":         KwThis,
+	"throw":        KwThrow,
+	"throws":       KwThrows,
+	"transient":    KwTransient,
+	"try":          KwTry,
+	"void":         KwVoid,
+	"volatile":     KwVolatile,
+	"while":        KwWhile,
+	"_":            KwUnderscore,
+}
+
+// literalKeywords palabras que son literales (JLS §3.10.3 y §3.10.8)
+var literalKeywords = map[string]TokenKind{
+	"true":  KwTrue,
+	"false": KwFalse,
+	"null":  KwNull,
+}
+
+// contextualKeywords palabras clave contextuales (JLS §3.9): solo tienen
+// significado especial en ciertas posiciones, por lo que el lexer las produce
+// como identificadores y es el parser quien las interpreta.
+var contextualKeywords = map[string]bool{
+	"var": true, "record": true, "yield": true, "sealed": true, "permits": true,
+	"non-sealed": true, "when": true, "module": true, "open": true, "opens": true,
+	"exports": true, "requires": true, "transitive": true, "uses": true,
+	"provides": true, "to": true, "with": true,
+}
+
+// identifierKind devuelve el tipo de una palabra: su palabra reservada, su
+// literal o identificador
+func identifierKind(word string) TokenKind {
+	if kind, ok := reservedWords[word]; ok {
+		return kind
+	}
+	if kind, ok := literalKeywords[word]; ok {
+		return kind
+	}
+	return KindIdentifier
+}
+
+// IsContextualKeyword indica si la palabra es una palabra clave contextual
+func IsContextualKeyword(word string) bool {
+	return contextualKeywords[word]
 }
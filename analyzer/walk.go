@@ -0,0 +1,261 @@
+// analyzer/walk.go
+package analyzer
+
+// Inspect recorre el árbol en profundidad llamando a visit con cada nodo. Si
+// visit devuelve false no se recorren los hijos de ese nodo.
+func Inspect(node Node, visit func(Node) bool) {
+	if node == nil || isNilNode(node) || !visit(node) {
+		return
+	}
+	for _, child := range children(node) {
+		Inspect(child, visit)
+	}
+}
+
+// isNilNode detecta interfaces que contienen un puntero nil
+func isNilNode(node Node) bool {
+	switch n := node.(type) {
+	case *Block:
+		return n == nil
+	case *TypeNode:
+		return n == nil
+	}
+	return false
+}
+
+// children devuelve los hijos directos de un nodo
+func children(node Node) []Node {
+	var list []Node
+	add := func(nodes ...Node) {
+		for _, n := range nodes {
+			if n != nil && !isNilNode(n) {
+				list = append(list, n)
+			}
+		}
+	}
+	addExprs := func(exprs []Expr) {
+		for _, x := range exprs {
+			add(x)
+		}
+	}
+	addStmts := func(stmts []Stmt) {
+		for _, s := range stmts {
+			add(s)
+		}
+	}
+	addDecls := func(decls []Decl) {
+		for _, d := range decls {
+			add(d)
+		}
+	}
+	addTypes := func(types []*TypeNode) {
+		for _, t := range types {
+			add(t)
+		}
+	}
+	addAnnotations := func(annotations []*Annotation) {
+		for _, a := range annotations {
+			add(a)
+		}
+	}
+
+	switch n := node.(type) {
+	case *CompilationUnit:
+		if n.Package != nil {
+			addAnnotations(n.Package.Annotations)
+		}
+		if n.Module != nil {
+			addAnnotations(n.Module.Annotations)
+		}
+		for _, t := range n.Types {
+			add(t)
+		}
+		for _, m := range n.Methods {
+			add(m)
+		}
+		addStmts(n.Statements)
+	case *ClassDecl:
+		addAnnotations(n.Modifiers.Annotations)
+		for _, param := range n.TypeParams {
+			add(param)
+		}
+		addTypes(n.Extends)
+		addTypes(n.Implements)
+		addTypes(n.Permits)
+		for _, component := range n.Components {
+			add(component)
+		}
+		for _, constant := range n.Constants {
+			add(constant)
+		}
+		addDecls(n.Members)
+	case *EnumConstant:
+		addAnnotations(n.Annotations)
+		addExprs(n.Args)
+		addDecls(n.Body)
+	case *InitializerBlock:
+		add(n.Body)
+	case *LocalClassStmt:
+		add(n.Decl)
+	case *TypeNode:
+		addAnnotations(n.Annotations)
+		for _, annotations := range n.DimAnnotations {
+			addAnnotations(annotations)
+		}
+		addTypes(n.Args)
+		add(n.Bound)
+	case *TypeParam:
+		addAnnotations(n.Annotations)
+		addTypes(n.Bounds)
+	case *Param:
+		addAnnotations(n.Modifiers.Annotations)
+		add(n.Type)
+	case *FieldDecl:
+		addAnnotations(n.Modifiers.Annotations)
+		add(n.Type)
+		for _, v := range n.Vars {
+			add(v)
+		}
+	case *MethodDecl:
+		addAnnotations(n.Modifiers.Annotations)
+		for _, param := range n.TypeParams {
+			add(param)
+		}
+		add(n.ResultType)
+		for _, param := range n.Params {
+			add(param)
+		}
+		addTypes(n.Throws)
+		if n.Body != nil {
+			add(n.Body)
+		}
+		add(n.Default)
+	case *VarDeclarator:
+		for _, annotations := range n.DimAnnotations {
+			addAnnotations(annotations)
+		}
+		add(n.Init)
+	case *Block:
+		addStmts(n.Stmts)
+	case *LocalVarDecl:
+		addAnnotations(n.Modifiers.Annotations)
+		add(n.Type)
+		for _, v := range n.Vars {
+			add(v)
+		}
+	case *ExprStmt:
+		add(n.X)
+	case *IfStmt:
+		add(n.Cond, n.Then, n.Else)
+	case *WhileStmt:
+		add(n.Cond, n.Body)
+	case *DoStmt:
+		add(n.Body, n.Cond)
+	case *ForStmt:
+		addStmts(n.Init)
+		add(n.Cond)
+		addExprs(n.Update)
+		add(n.Body)
+	case *ForEachStmt:
+		addAnnotations(n.Modifiers.Annotations)
+		add(n.VarType, n.Iterable, n.Body)
+	case *LabeledStmt:
+		add(n.Body)
+	case *SwitchStmt:
+		add(n.Selector)
+		for _, c := range n.Cases {
+			add(c)
+		}
+	case *SwitchExpr:
+		add(n.Selector)
+		for _, c := range n.Cases {
+			add(c)
+		}
+	case *SwitchCase:
+		addExprs(n.Labels)
+		if n.Pattern != nil {
+			add(n.Pattern)
+		}
+		add(n.Guard)
+		addStmts(n.Body)
+	case *Pattern:
+		add(n.Type)
+		for _, component := range n.Components {
+			add(component)
+		}
+	case *YieldStmt:
+		add(n.Value)
+	case *ReturnStmt:
+		add(n.Value)
+	case *TryStmt:
+		add(n.Resources...)
+		add(n.Body)
+		for _, catch := range n.Catches {
+			add(catch)
+		}
+		if n.Finally != nil {
+			add(n.Finally)
+		}
+	case *CatchClause:
+		addAnnotations(n.Modifiers.Annotations)
+		addTypes(n.Types)
+		add(n.Body)
+	case *ThrowStmt:
+		add(n.Value)
+	case *AssertStmt:
+		add(n.Cond, n.Message)
+	case *SynchronizedStmt:
+		add(n.Lock, n.Body)
+	case *ParenExpr:
+		add(n.X)
+	case *UnaryExpr:
+		add(n.X)
+	case *BinaryExpr:
+		add(n.X, n.Y)
+	case *AssignExpr:
+		add(n.Target, n.Value)
+	case *FieldAccess:
+		add(n.X)
+	case *MethodCall:
+		add(n.Target)
+		addTypes(n.TypeArgs)
+		addExprs(n.Args)
+	case *ConstructorCall:
+		addExprs(n.Args)
+	case *ConditionalExpr:
+		add(n.Cond, n.Then, n.Else)
+	case *CastExpr:
+		add(n.Type, n.X)
+	case *InstanceOfExpr:
+		add(n.X, n.Type)
+	case *ArrayAccess:
+		add(n.X, n.Index)
+	case *NewExpr:
+		add(n.Outer)
+		add(n.Type)
+		addExprs(n.Args)
+		addDecls(n.Body)
+	case *NewArrayExpr:
+		add(n.Type)
+		addExprs(n.Lengths)
+		if n.Init != nil {
+			add(n.Init)
+		}
+	case *ArrayInitializer:
+		addExprs(n.Elems)
+	case *ClassLiteral:
+		add(n.Type)
+	case *Annotation:
+		for _, arg := range n.Args {
+			add(arg.Value)
+		}
+	case *LambdaExpr:
+		for _, param := range n.Params {
+			add(param)
+		}
+		add(n.Body)
+	case *MethodRef:
+		add(n.X, n.Type)
+	}
+	return list
+}
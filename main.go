@@ -7,16 +7,20 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"apiLexy/analyzer"
 )
 
 type OptimizedRequest struct {
-	Code           string `json:"code"`
-	EnableOptimize bool   `json:"enable_optimize"`
-	EnableMonitor  bool   `json:"enable_monitor"`
+	Code           string              `json:"code"`
+	EnableOptimize bool                `json:"enable_optimize"`
+	EnableMonitor  bool                `json:"enable_monitor"`
+	Lang           string              `json:"lang"`
+	Rules          analyzer.RuleConfig `json:"rules"`
 }
 
 type OptimizedResponse struct {
@@ -25,6 +29,8 @@ type OptimizedResponse struct {
 	SemanticOK          bool                          `json:"semantic_ok"`
 	SyntaxErrors        []string                      `json:"syntax_errors"`
 	SemanticErrors      []string                      `json:"semantic_errors"`
+	Diagnostics         []analyzer.Diagnostic         `json:"diagnostics"`
+	Locale              string                        `json:"locale"`
 	AnalysisTime        string                        `json:"analysis_time"`
 	Summary             AnalysisSummary               `json:"summary"`
 	PerformanceStats    *analyzer.PerformanceStats    `json:"performance_stats,omitempty"`
@@ -55,6 +61,7 @@ var (
 	optimizedLexer    *analyzer.OptimizedLexer
 	semanticAnalyzer  *analyzer.EnhancedSemanticAnalyzer
 	stringLibrary     *analyzer.StringLibrary
+	ruleProfile       analyzer.RuleConfig // perfil de reglas del servidor
 )
 
 func init() {
@@ -106,6 +113,13 @@ func optimizedAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Reglas: el perfil del servidor y encima la configuración de la petición
+	rules, err := analyzer.NewRuleSet(ruleProfile, req.Rules)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Configuración de reglas inválida: %v", err), http.StatusBadRequest)
+		return
+	}
+
 	log.Printf("Analizando código de %d caracteres (optimizado: %v, monitor: %v)", 
 		len(req.Code), req.EnableOptimize, req.EnableMonitor)
 
@@ -126,24 +140,32 @@ func optimizedAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Lexer generó %d tokens", len(tokens))
 
 	// Análisis sintáctico
-	syntaxOK, syntaxErrors := analyzer.Parse(tokens)
-	log.Printf("Parser encontró %d errores sintácticos", len(syntaxErrors))
+	syntaxOK, syntaxDiagnostics := analyzer.Parse(tokens)
+	log.Printf("Parser encontró %d errores sintácticos", len(syntaxDiagnostics))
 
 	// Análisis semántico optimizado o estándar
 	var semanticOK bool
-	var semanticErrors []string
+	var semanticDiagnostics []analyzer.Diagnostic
 	if req.EnableOptimize {
-		semanticOK, semanticErrors = semanticAnalyzer.AnalyzeOptimized(tokens)
+		semanticOK, semanticDiagnostics = semanticAnalyzer.AnalyzeOptimizedWithRules(tokens, rules)
 	} else {
-		semanticOK, semanticErrors = analyzer.AnalyzeSemantics(tokens)
+		semanticOK, semanticDiagnostics = analyzer.AnalyzeSemanticsWithRules(tokens, rules)
 	}
-	log.Printf("Analizador semántico encontró %d errores", len(semanticErrors))
+	log.Printf("Analizador semántico encontró %d errores", len(semanticDiagnostics))
+
+	// Idioma de los mensajes: campo lang o cabecera Accept-Language
+	locale := analyzer.MatchLocale(req.Lang, r.Header.Get("Accept-Language"))
+	tokens = analyzer.LocalizeTokens(tokens, locale)
+	syntaxDiagnostics = analyzer.Localize(syntaxDiagnostics, locale)
+	semanticDiagnostics = analyzer.Localize(semanticDiagnostics, locale)
+	diagnostics := append(syntaxDiagnostics, semanticDiagnostics...)
+	analyzer.SortDiagnostics(diagnostics)
 
 	// Detectar métodos de String utilizados
 	stringMethodsFound := detectStringMethods(tokens)
 
 	// Generar resumen optimizado
-	summary := generateOptimizedSummary(tokens, syntaxErrors, semanticErrors, req.Code, stringMethodsFound)
+	summary := generateOptimizedSummary(tokens, diagnostics, req.Code, stringMethodsFound)
 	
 	// Generar reporte de optimización si está habilitado
 	var optimizationReport *OptimizationReport
@@ -164,8 +186,10 @@ func optimizedAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 		Tokens:             tokens,
 		SyntaxOK:           syntaxOK,
 		SemanticOK:         semanticOK,
-		SyntaxErrors:       syntaxErrors,
-		SemanticErrors:     semanticErrors,
+		SyntaxErrors:       analyzer.Messages(syntaxDiagnostics),
+		SemanticErrors:     analyzer.Messages(semanticDiagnostics),
+		Diagnostics:        diagnostics,
+		Locale:             locale,
 		AnalysisTime:       analysisTime.String(),
 		Summary:            summary,
 		PerformanceStats:   performanceStats,
@@ -173,6 +197,8 @@ func optimizedAnalyzeHandler(w http.ResponseWriter, r *http.Request) {
 		StringMethodsFound: stringMethodsFound,
 	}
 
+	w.Header().Set("Content-Language", locale)
+	w.Header().Set("Vary", "Accept-Language")
 	if err := json.NewEncoder(w).Encode(res); err != nil {
 		log.Printf("Error codificando respuesta: %v", err)
 		http.Error(w, "Error codificando respuesta", http.StatusInternalServerError)
@@ -187,7 +213,7 @@ func detectStringMethods(tokens []analyzer.Token) []string {
 	stringMethods := stringLibrary.GetStringMethods()
 	
 	for i := 0; i < len(tokens)-2; i++ {
-		if tokens[i].Type == "identifier" && tokens[i+1].Value == "." && tokens[i+2].Type == "identifier" {
+		if tokens[i].Type == analyzer.KindIdentifier && tokens[i+1].Type == analyzer.KindDot && tokens[i+2].Type == analyzer.KindIdentifier {
 			methodName := tokens[i+2].Value
 			for _, validMethod := range stringMethods {
 				if methodName == validMethod {
@@ -205,7 +231,7 @@ func detectStringMethods(tokens []analyzer.Token) []string {
 	return result
 }
 
-func generateOptimizedSummary(tokens []analyzer.Token, syntaxErrors, semanticErrors []string, code string, stringMethods []string) AnalysisSummary {
+func generateOptimizedSummary(tokens []analyzer.Token, diagnostics []analyzer.Diagnostic, code string, stringMethods []string) AnalysisSummary {
 	// Contar líneas de manera eficiente
 	lines := 1
 	for _, char := range code {
@@ -226,39 +252,32 @@ func generateOptimizedSummary(tokens []analyzer.Token, syntaxErrors, semanticErr
 	
 	for i, token := range tokens {
 		// Contar declaraciones de variables
-		if token.Type == "keyword" && variableTypes[token.Value] {
-			if i+1 < len(tokens) && tokens[i+1].Type == "identifier" {
+		if (token.Type.IsKeyword() || token.Type == analyzer.KindIdentifier) && variableTypes[token.Value] {
+			if i+1 < len(tokens) && tokens[i+1].Type == analyzer.KindIdentifier {
 				variables++
 			}
 		}
-		
-		// Contar métodos (mejorado)
-		if token.Value == "main" || (token.Type == "identifier" && i+1 < len(tokens) && tokens[i+1].Type == "lparen") {
-			if i > 0 && (tokens[i-1].Value == "void" || variableTypes[tokens[i-1].Value] || tokens[i-1].Value == "public" || tokens[i-1].Value == "static") {
-				methods++
-			}
-		}
 	}
 
-	// Contar errores y warnings de manera optimizada
+	// Contar métodos sobre el árbol, incluidos los de clases anidadas,
+	// enums, records e interfaces (los constructores no cuentan)
+	unit, _ := analyzer.ParseFile(tokens)
+	analyzer.Inspect(unit, func(node analyzer.Node) bool {
+		if method, ok := node.(*analyzer.MethodDecl); ok && !method.IsConstructor() {
+			methods++
+		}
+		return true
+	})
+
+	// Contar errores y warnings según la gravedad de cada diagnóstico
 	errorCount := 0
 	warningCount := 0
 	
-	errorKeywords := []string{"Error", "error"}
-	warningKeywords := []string{"Advertencia", "Warning", "warning"}
-	
-	for _, err := range syntaxErrors {
-		if containsAny(err, errorKeywords) {
-			errorCount++
-		} else if containsAny(err, warningKeywords) {
-			warningCount++
-		}
-	}
-	
-	for _, err := range semanticErrors {
-		if containsAny(err, errorKeywords) {
+	for _, diagnostic := range diagnostics {
+		switch diagnostic.Severity {
+		case analyzer.SeverityError:
 			errorCount++
-		} else if containsAny(err, warningKeywords) {
+		case analyzer.SeverityWarning:
 			warningCount++
 		}
 	}
@@ -293,13 +312,13 @@ func generateOptimizationReport(tokens []analyzer.Token, code string) *Optimizat
 	hasComplexExpressions := false
 	
 	for i, token := range tokens {
-		if token.Type == "string" || (token.Type == "identifier" && i+1 < len(tokens) && tokens[i+1].Value == ".") {
+		if token.Type == analyzer.KindString || (token.Type == analyzer.KindIdentifier && i+1 < len(tokens) && tokens[i+1].Type == analyzer.KindDot) {
 			hasStringOperations = true
 		}
 		if token.Value == "for" || token.Value == "while" {
 			hasLoops = true
 		}
-		if token.Type == "operator" && len(token.Value) > 1 {
+		if token.Type.IsOperator() && len(token.Value) > 1 {
 			hasComplexExpressions = true
 		}
 	}
@@ -335,19 +354,6 @@ func generateOptimizationReport(tokens []analyzer.Token, code string) *Optimizat
 	}
 }
 
-func containsAny(str string, substrings []string) bool {
-	for _, substr := range substrings {
-		if len(str) >= len(substr) {
-			for i := 0; i <= len(str)-len(substr); i++ {
-				if str[i:i+len(substr)] == substr {
-					return true
-				}
-			}
-		}
-	}
-	return false
-}
-
 // Endpoint para información extendida del analizador
 func enhancedInfoHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -368,18 +374,34 @@ func enhancedInfoHandler(w http.ResponseWriter, r *http.Request) {
 			"Recomendaciones de optimización automáticas",
 			"Soporte para tipos de datos extendidos",
 			"Validación de caracteres escapados en strings",
+			"Recuperación de errores sintácticos: se reportan los primeros errores sin cascadas",
+			"Diagnósticos estructurados con código estable, gravedad, fase, posición y notas",
+			"Mensajes en español e inglés, elegidos con el campo lang o la cabecera Accept-Language",
+			"Reglas configurables: activar, desactivar y cambiar la gravedad de cada comprobación",
 		},
+		"locales": analyzer.Locales(),
 		"supported_constructs": []string{
-			"Clases públicas y privadas",
+			"Clases, interfaces, enums y records (anidados, locales y anónimos)",
+			"Tipos sealed con permits, non-sealed, clases abstractas y final",
+			"Bloques inicializadores y constructores compactos de records",
+			"Genéricos: parámetros de tipo con límites, comodines, diamante y llamadas con argumentos explícitos",
+			"Arrays: tipos int[] e int a[], creación multidimensional, inicializadores e indexación",
+			"package, import (de tipo, .*, static) y module-info.java, con avisos de imports duplicados o sin usar",
+			"Anotaciones y tipos @interface, con comprobación de @Override, @FunctionalInterface y @SafeVarargs",
+			"Lambdas y referencias a método, con tipo destino de las interfaces de java.util.function",
 			"Método main y métodos personalizados",
 			"Variables (int, String, char, float, double, boolean, byte, short, long)",
-			"Estructuras de control (if, for, while, do-while)",
+			"Estructuras de control (if/else, for, for-each, while, do-while, etiquetas, break, continue, return, throw, assert, synchronized)",
+			"switch clásico, con flechas y como expresión (yield, patrones y guardas when)",
+			"Excepciones: try/catch/finally, multi-catch y try-with-resources",
 			"System.out.println y System.out.print",
 			"Métodos de String (equals, length, substring, charAt, etc.)",
 			"Operadores aritméticos y lógicos",
 			"Expresiones complejas y concatenación",
 			"Caracteres escapados en strings",
+			"Bloques de texto (\"\"\" ... \"\"\")",
 			"Comentarios de línea y bloque",
+			"Comentarios Javadoc con etiquetas (@param, @return, @throws)",
 		},
 		"optimization_features": []string{
 			"Pool de tokens reutilizables",
@@ -402,6 +424,19 @@ func enhancedInfoHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(info)
 }
 
+// Endpoint con las reglas configurables y el perfil del servidor
+func rulesHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Content-Type", "application/json")
+
+	response := map[string]interface{}{
+		"rules":   analyzer.Rules(),
+		"profile": ruleProfile,
+	}
+
+	json.NewEncoder(w).Encode(response)
+}
+
 // Endpoint para validar solo strings
 func stringValidationHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
@@ -444,7 +479,8 @@ func stringValidationHandler(w http.ResponseWriter, r *http.Request) {
 		"results":           results,
 		"available_methods": stringLibrary.GetStringMethods(),
 		"validation_rules": []string{
-			"Caracteres escapados válidos: \\n, \\t, \\r, \\\\, \\\", \\'",
+			"Caracteres escapados válidos: \\b, \\t, \\n, \\f, \\r, \\s, \\\\, \\\", \\'",
+			"Escapes octales (\\0 a \\377) y Unicode (\\uXXXX)",
 			"No se permiten caracteres de control no escapados",
 			"Longitud máxima recomendada: 1000 caracteres",
 		},
@@ -474,10 +510,43 @@ func main() {
 	// Configurar logging optimizado
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	
+	// Catálogos de mensajes adicionales: un <idioma>.json por idioma
+	localesDir := os.Getenv("LOCALES_DIR")
+	if localesDir == "" {
+		localesDir = "locales"
+	}
+	if _, err := os.Stat(localesDir); err == nil {
+		if err := analyzer.LoadLocales(localesDir); err != nil {
+			log.Fatalf("❌ Error cargando catálogos de mensajes: %v", err)
+		}
+	}
+	if err := analyzer.CheckCatalogs(); err != nil {
+		// Los mensajes que falten se muestran en el idioma por defecto
+		log.Printf("⚠️ %v\nLos mensajes que faltan se mostrarán en '%s'", err, analyzer.DefaultLocale)
+	}
+	
+	// Perfil de reglas del servidor, p. ej. las reglas estrictas del curso
+	profilePath := os.Getenv("RULES_PROFILE")
+	if profilePath == "" {
+		profilePath = "rules.json"
+	}
+	if _, err := os.Stat(profilePath); err == nil {
+		profile, err := analyzer.LoadRuleConfig(profilePath)
+		if err != nil {
+			log.Fatalf("❌ Error cargando el perfil de reglas: %v", err)
+		}
+		if _, err := analyzer.NewRuleSet(profile); err != nil {
+			log.Fatalf("❌ Perfil de reglas inválido %s: %v", profilePath, err)
+		}
+		ruleProfile = profile
+		log.Printf("Perfil de reglas cargado de %s", profilePath)
+	}
+	
 	// Rutas optimizadas
 	http.Handle("/analyze", optimizedLoggingMiddleware(http.HandlerFunc(optimizedAnalyzeHandler)))
 	http.Handle("/syntax", optimizedLoggingMiddleware(http.HandlerFunc(syntaxHandler)))
 	http.Handle("/info", optimizedLoggingMiddleware(http.HandlerFunc(enhancedInfoHandler)))
+	http.Handle("/rules", optimizedLoggingMiddleware(http.HandlerFunc(rulesHandler)))
 	http.Handle("/validate-strings", optimizedLoggingMiddleware(http.HandlerFunc(stringValidationHandler)))
 	
 	// Mantener compatibilidad con endpoints originales
@@ -518,6 +587,7 @@ func main() {
 	fmt.Println("   • POST /syntax           - Solo análisis sintáctico")
 	fmt.Println("   • POST /validate-strings - Validación específica de strings")
 	fmt.Println("   • GET  /info             - Información completa del analizador")
+	fmt.Println("   • GET  /rules            - Reglas configurables y perfil del servidor")
 	fmt.Println("   • GET  /health           - Estado detallado del servidor")
 	fmt.Println("==========================================")
 	fmt.Println("✨ Nuevas características v3.0:")
@@ -530,6 +600,7 @@ func main() {
 	fmt.Println("   • 💾 Reducción de memoria del 20-40%")
 	fmt.Println("   • ⚡ Incremento de velocidad del 15-25%")
 	fmt.Println("==========================================")
+	fmt.Printf("🌐 Idiomas de los mensajes: %s\n", strings.Join(analyzer.Locales(), ", "))
 	fmt.Printf("💻 Sistema: %d CPU cores, GC optimizado\n", runtime.NumCPU())
 	fmt.Printf("🕐 Iniciado: %s\n", startTime.Format("15:04:05"))
 	fmt.Println("==========================================")